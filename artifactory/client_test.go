@@ -0,0 +1,134 @@
+package artifactory
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+// testClient configures a provider against the fake and returns its client
+func testClient(t *testing.T, raw map[string]interface{}) artifactory.Client {
+	raw["url"] = testAccFake.URL
+	raw["username"] = testAccFake.username
+	raw["password"] = testAccFake.password
+
+	rc, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := Provider().(*schema.Provider)
+	if err := p.Configure(terraform.NewResourceConfig(rc)); err != nil {
+		t.Fatal(err)
+	}
+	return p.Meta().(artifactory.Client)
+}
+
+// TestClient_concurrent hammers a single client from many goroutines, run it
+// with -race to detect unsynchronized state
+func TestClient_concurrent(t *testing.T) {
+	testAccFakeOnly(t)
+	defer testAccFake.reset()
+
+	c := testClient(t, map[string]interface{}{})
+
+	const workers = 8
+	for i := 0; i < workers; i++ {
+		key := fmt.Sprintf("concurrent-%d", i)
+		repo := &artifactory.LocalRepositoryConfiguration{Key: key, RClass: "local"}
+		if err := c.CreateRepository(key, repo); err != nil {
+			t.Fatal(err)
+		}
+		defer c.DeleteRepository(key)
+	}
+
+	testAccFake.setLatency(20 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*10)
+	for i := 0; i < workers*10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("concurrent-%d", i%workers)
+
+			if i%2 == 0 {
+				var repo artifactory.LocalRepositoryConfiguration
+				errs <- c.GetRepository(key, &repo)
+				return
+			}
+
+			desc := fmt.Sprintf("update %d", i)
+			errs <- c.UpdateRepository(key, &artifactory.LocalRepositoryConfiguration{Key: key, Description: desc})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	if peak := testAccFake.peakConcurrency(); peak < 2 {
+		t.Errorf("expected requests to be served concurrently, at most %d were", peak)
+	}
+}
+
+func TestClient_maxConcurrentRequests(t *testing.T) {
+	testAccFakeOnly(t)
+	defer testAccFake.reset()
+
+	c := testClient(t, map[string]interface{}{"max_concurrent_requests": 2})
+	testAccFake.setLatency(20 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Ping(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak := testAccFake.peakConcurrency(); peak > 2 {
+		t.Errorf("expected at most 2 concurrent requests, %d were served at the same time", peak)
+	}
+}
+
+// TestClient_lostAnswers checks requests doing something new every time they
+// are sent are not retried once Artifactory handled them
+func TestClient_lostAnswers(t *testing.T) {
+	testAccFakeOnly(t)
+	defer testAccFake.reset()
+
+	c := testClient(t, map[string]interface{}{"retry_wait_min": "10ms"})
+
+	key := "acctest-lost-answer"
+	testAccFake.addFault(&fakeFault{method: "PUT", path: "repositories/" + key, status: 502, lost: true, times: 1})
+	defer c.DeleteRepository(key)
+	err := c.CreateRepository(key, &artifactory.LocalRepositoryConfiguration{Key: key, RClass: "local"})
+	if err == nil || !strings.Contains(err.Error(), "502 Bad Gateway") {
+		t.Errorf("expected the lost answer to creating the repository to be returned, got: %v", err)
+	}
+
+	if _, err := c.CreateAPIKey(); err != nil {
+		t.Fatal(err)
+	}
+	defer testAccFake.removeOutOfBand("security/apiKey/admin")
+	testAccFake.addFault(&fakeFault{method: "PUT", path: "security/apiKey", status: 502, lost: true, times: 1})
+	if _, err := c.RegenerateAPIKey(); err == nil || !strings.Contains(err.Error(), "502 Bad Gateway") {
+		t.Errorf("expected the lost answer to regenerating the API key to be returned, got: %v", err)
+	}
+}
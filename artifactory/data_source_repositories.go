@@ -0,0 +1,107 @@
+package artifactory
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+func dataSourceRepositories() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceRepositoriesRead,
+		Schema: map[string]*schema.Schema{
+			"type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(types, true),
+			},
+			"package_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(packageTypes, true),
+			},
+			"key_regex": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.ValidateRegexp,
+			},
+			"keys": &schema.Schema{
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+			"repositories": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"package_type": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"url": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRepositoriesRead(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	repoType := d.Get("type").(string)
+	packageType := d.Get("package_type").(string)
+	keyRegex := d.Get("key_regex").(string)
+
+	re, err := regexp.Compile(keyRegex)
+	if err != nil {
+		return err
+	}
+
+	repos, err := c.ListRepositories(repoType, packageType)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(repos))
+	l := make([]interface{}, 0, len(repos))
+	for _, r := range repos {
+		if !re.MatchString(r.Key) {
+			continue
+		}
+		keys = append(keys, r.Key)
+		l = append(l, map[string]interface{}{
+			"key":          r.Key,
+			"type":         strings.ToLower(r.Type),
+			"package_type": strings.ToLower(r.PackageType),
+			"description":  r.Description,
+			"url":          r.URL,
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(fmt.Sprintf("%s/%s/%s", repoType, packageType, keyRegex))))
+	d.Set("keys", keys)
+	d.Set("repositories", l)
+
+	return nil
+}
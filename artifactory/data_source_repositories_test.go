@@ -0,0 +1,60 @@
+package artifactory
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+const testAccDataSourceRepositories_repos = `
+resource "artifactory_local_repository" "npm" {
+	key          = "acctest-list-npm-local"
+	package_type = "npm"
+	description  = "npm packages"
+}
+
+resource "artifactory_remote_repository" "npm" {
+	key          = "acctest-list-npm-remote"
+	package_type = "npm"
+	url          = "https://registry.npmjs.org/"
+}
+
+resource "artifactory_local_repository" "generic" {
+	key = "acctest-list-generic-local"
+}`
+
+// the repositories are created in a prior step, as a data source
+// depending on resources is read again on every plan
+var testAccDataSourceRepositories_filter = testAccDataSourceRepositories_repos + `
+
+data "artifactory_repositories" "npm_local" {
+	type         = "local"
+	package_type = "npm"
+	key_regex    = "^acctest-list-"
+}`
+
+func TestAccDataSourceRepositories_filter(t *testing.T) {
+	dataSourceName := "data.artifactory_repositories.npm_local"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy("artifactory_local_repository.npm"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccDataSourceRepositories_repos,
+			},
+			resource.TestStep{
+				Config: testAccDataSourceRepositories_filter,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "keys.#", "1"),
+					resource.TestCheckResourceAttr(dataSourceName, "keys.0", "acctest-list-npm-local"),
+					resource.TestCheckResourceAttr(dataSourceName, "repositories.#", "1"),
+					resource.TestCheckResourceAttr(dataSourceName, "repositories.0.type", "local"),
+					resource.TestCheckResourceAttr(dataSourceName, "repositories.0.package_type", "npm"),
+					resource.TestCheckResourceAttr(dataSourceName, "repositories.0.description", "npm packages"),
+					resource.TestCheckResourceAttrSet(dataSourceName, "repositories.0.url"),
+				),
+			},
+		},
+	})
+}
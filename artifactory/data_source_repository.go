@@ -0,0 +1,117 @@
+package artifactory
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+func dataSourceRepository() *schema.Resource {
+	// expose the attributes of every repository class, named as in the resources
+	s := map[string]*schema.Schema{
+		"type": &schema.Schema{
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+	for _, r := range []*schema.Resource{resourceLocalRepository(), resourceRemoteRepository(), resourceVirtualRepository()} {
+		for k, v := range r.Schema {
+			if !dataSourceRepositoryExcluded[k] {
+				s[k] = mergeComputedSchema(s[k], computedSchema(v))
+			}
+		}
+	}
+	s["key"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Required: true,
+	}
+
+	return &schema.Resource{
+		Read:   dataSourceRepositoryRead,
+		Schema: s,
+	}
+}
+
+// dataSourceRepositoryExcluded are the arguments of the repository resources
+// that are never read back from Artifactory
+var dataSourceRepositoryExcluded = map[string]bool{
+	"password":            true,
+	"password_wo":         true,
+	"password_wo_version": true,
+}
+
+// computedSchema returns a copy of a resource attribute for use as a computed
+// data source attribute
+func computedSchema(s *schema.Schema) *schema.Schema {
+	elem := s.Elem
+	if r, ok := elem.(*schema.Resource); ok {
+		attrs := make(map[string]*schema.Schema, len(r.Schema))
+		for k, v := range r.Schema {
+			attrs[k] = computedSchema(v)
+		}
+		elem = &schema.Resource{Schema: attrs}
+	}
+
+	return &schema.Schema{
+		Type:      s.Type,
+		Elem:      elem,
+		Set:       s.Set,
+		Computed:  true,
+		Sensitive: s.Sensitive,
+	}
+}
+
+// mergeComputedSchema combines the blocks of the same name of several
+// repository classes, such as helm, into one holding all their attributes
+func mergeComputedSchema(existing, s *schema.Schema) *schema.Schema {
+	if existing == nil {
+		return s
+	}
+
+	existingElem, ok := existing.Elem.(*schema.Resource)
+	elem, isBlock := s.Elem.(*schema.Resource)
+	if !ok || !isBlock {
+		return s
+	}
+
+	for k, v := range existingElem.Schema {
+		if _, exists := elem.Schema[k]; !exists {
+			elem.Schema[k] = v
+		}
+	}
+	return s
+}
+
+func dataSourceRepositoryRead(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	key := d.Get("key").(string)
+
+	var repo artifactory.VirtualRepositoryConfiguration
+	if err := c.GetRepository(key, &repo); err != nil {
+		return err
+	}
+
+	d.SetId(key)
+	var err error
+	switch repo.RClass {
+	case "local":
+		err = resourceLocalRepositoryRead(d, m)
+	case "remote":
+		err = resourceRemoteRepositoryRead(d, m)
+	case "virtual":
+		err = resourceVirtualRepositoryRead(d, m)
+	default:
+		return fmt.Errorf("Repository %s has unknown type '%s'", key, repo.RClass)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if d.Id() == "" {
+		return fmt.Errorf("Repository %s not found", key)
+	}
+
+	return nil
+}
@@ -0,0 +1,343 @@
+package artifactory
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+const testAccDataSourceRepository_local = `
+resource "artifactory_local_repository" "foobar" {
+	key             = "acctest-data-local"
+	package_type    = "npm"
+	repo_layout_ref = "npm-default"
+	description     = "desc"
+	property_sets   = [ "artifactory" ]
+}
+
+data "artifactory_repository" "foobar" {
+	key = "${artifactory_local_repository.foobar.key}"
+}`
+
+func TestAccDataSourceRepository_local(t *testing.T) {
+	dataSourceName := "data.artifactory_repository.foobar"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy("artifactory_local_repository.foobar"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccDataSourceRepository_local,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "key", "acctest-data-local"),
+					resource.TestCheckResourceAttr(dataSourceName, "type", "local"),
+					resource.TestCheckResourceAttr(dataSourceName, "package_type", "npm"),
+					resource.TestCheckResourceAttr(dataSourceName, "repo_layout_ref", "npm-default"),
+					resource.TestCheckResourceAttr(dataSourceName, "description", "desc"),
+					resource.TestCheckResourceAttr(dataSourceName, "property_sets.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceRepository_remote = `
+resource "artifactory_remote_repository" "foobar" {
+	key                 = "acctest-data-remote"
+	package_type        = "npm"
+	url                 = "https://registry.npmjs.org/"
+	username            = "user"
+	password_wo         = "secret"
+	password_wo_version = 1
+}
+
+data "artifactory_repository" "foobar" {
+	key = "${artifactory_remote_repository.foobar.key}"
+}`
+
+func TestAccDataSourceRepository_remote(t *testing.T) {
+	dataSourceName := "data.artifactory_repository.foobar"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy("artifactory_remote_repository.foobar"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccDataSourceRepository_remote,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "type", "remote"),
+					resource.TestCheckResourceAttr(dataSourceName, "package_type", "npm"),
+					resource.TestCheckResourceAttr(dataSourceName, "url", "https://registry.npmjs.org/"),
+					resource.TestCheckResourceAttr(dataSourceName, "username", "user"),
+					resource.TestCheckNoResourceAttr(dataSourceName, "password"),
+					resource.TestCheckNoResourceAttr(dataSourceName, "password_wo"),
+					resource.TestCheckNoResourceAttr(dataSourceName, "password_wo_version"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceRepository_virtual = `
+resource "artifactory_local_repository" "foobar" {
+	key          = "acctest-data-virtual-local"
+	package_type = "npm"
+}
+
+resource "artifactory_virtual_repository" "foobar" {
+	key                     = "acctest-data-virtual"
+	package_type            = "npm"
+	repositories            = [ "${artifactory_local_repository.foobar.key}" ]
+	default_deployment_repo = "${artifactory_local_repository.foobar.key}"
+}
+
+data "artifactory_repository" "foobar" {
+	key = "${artifactory_virtual_repository.foobar.key}"
+}`
+
+func TestAccDataSourceRepository_virtual(t *testing.T) {
+	dataSourceName := "data.artifactory_repository.foobar"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy("artifactory_virtual_repository.foobar"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccDataSourceRepository_virtual,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "type", "virtual"),
+					resource.TestCheckResourceAttr(dataSourceName, "repositories.#", "1"),
+					resource.TestCheckResourceAttr(dataSourceName, "default_deployment_repo", "acctest-data-virtual-local"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceRepository_localFormats = `
+resource "artifactory_local_repository" "alpine" {
+	key          = "acctest-data-local-alpine"
+	package_type = "alpine"
+
+	alpine {
+		primary_keypair_ref = "acctest-keypair"
+	}
+}
+
+resource "artifactory_local_repository" "cargo" {
+	key          = "acctest-data-local-cargo"
+	package_type = "cargo"
+
+	cargo {
+		anonymous_access = true
+	}
+}
+
+resource "artifactory_local_repository" "helm" {
+	key          = "acctest-data-local-helm"
+	package_type = "helm"
+
+	helm {
+		chart_version_suffix = "-snapshot"
+	}
+}
+
+resource "artifactory_local_repository" "terraform" {
+	key          = "acctest-data-local-terraform"
+	package_type = "terraform"
+
+	terraform {
+		registry_type = "module"
+	}
+}
+
+data "artifactory_repository" "alpine" {
+	key = "${artifactory_local_repository.alpine.key}"
+}
+
+data "artifactory_repository" "cargo" {
+	key = "${artifactory_local_repository.cargo.key}"
+}
+
+data "artifactory_repository" "helm" {
+	key = "${artifactory_local_repository.helm.key}"
+}
+
+data "artifactory_repository" "terraform" {
+	key = "${artifactory_local_repository.terraform.key}"
+}`
+
+func TestAccDataSourceRepository_localFormats(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy("artifactory_local_repository.helm"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccDataSourceRepository_localFormats,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.artifactory_repository.alpine", "alpine.0.primary_keypair_ref", "acctest-keypair"),
+					resource.TestCheckResourceAttr("data.artifactory_repository.cargo", "cargo.0.anonymous_access", "true"),
+					resource.TestCheckResourceAttr("data.artifactory_repository.helm", "helm.0.chart_version_suffix", "-snapshot"),
+					resource.TestCheckResourceAttr("data.artifactory_repository.terraform", "terraform.0.registry_type", "module"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceRepository_remoteFormats = `
+resource "artifactory_remote_repository" "cargo" {
+	key          = "acctest-data-remote-cargo"
+	package_type = "cargo"
+	url          = "https://index.crates.io/"
+
+	cargo {
+		git_registry_url = "https://github.com/rust-lang/crates.io-index"
+		anonymous_access = true
+	}
+}
+
+resource "artifactory_remote_repository" "cocoapods" {
+	key          = "acctest-data-remote-cocoapods"
+	package_type = "cocoapods"
+	url          = "https://github.com/"
+
+	cocoapods {
+		specs_repo_url = "https://github.com/CocoaPods/Specs"
+	}
+}
+
+resource "artifactory_remote_repository" "go" {
+	key          = "acctest-data-remote-go"
+	package_type = "go"
+	url          = "https://proxy.golang.org/"
+
+	go {
+		vcs_download_url = "https://vcs.example.com/download"
+	}
+}
+
+resource "artifactory_remote_repository" "helm" {
+	key          = "acctest-data-remote-helm"
+	package_type = "helm"
+	url          = "https://charts.helm.sh/stable"
+
+	helm {
+		charts_base_url = "https://charts.example.com"
+	}
+}
+
+resource "artifactory_remote_repository" "terraform" {
+	key          = "acctest-data-remote-terraform"
+	package_type = "terraform"
+	url          = "https://github.com/"
+
+	terraform {
+		registry_url  = "https://registry.terraform.io"
+		providers_url = "https://releases.hashicorp.com"
+	}
+}
+
+data "artifactory_repository" "cargo" {
+	key = "${artifactory_remote_repository.cargo.key}"
+}
+
+data "artifactory_repository" "cocoapods" {
+	key = "${artifactory_remote_repository.cocoapods.key}"
+}
+
+data "artifactory_repository" "go" {
+	key = "${artifactory_remote_repository.go.key}"
+}
+
+data "artifactory_repository" "helm" {
+	key = "${artifactory_remote_repository.helm.key}"
+}
+
+data "artifactory_repository" "terraform" {
+	key = "${artifactory_remote_repository.terraform.key}"
+}`
+
+func TestAccDataSourceRepository_remoteFormats(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy("artifactory_remote_repository.helm"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccDataSourceRepository_remoteFormats,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.artifactory_repository.cargo", "cargo.0.git_registry_url", "https://github.com/rust-lang/crates.io-index"),
+					resource.TestCheckResourceAttr("data.artifactory_repository.cargo", "cargo.0.anonymous_access", "true"),
+					resource.TestCheckResourceAttr("data.artifactory_repository.cocoapods", "cocoapods.0.specs_repo_url", "https://github.com/CocoaPods/Specs"),
+					resource.TestCheckResourceAttr("data.artifactory_repository.go", "go.0.vcs_download_url", "https://vcs.example.com/download"),
+					resource.TestCheckResourceAttr("data.artifactory_repository.helm", "helm.0.charts_base_url", "https://charts.example.com"),
+					resource.TestCheckResourceAttr("data.artifactory_repository.terraform", "terraform.0.registry_url", "https://registry.terraform.io"),
+					resource.TestCheckResourceAttr("data.artifactory_repository.terraform", "terraform.0.providers_url", "https://releases.hashicorp.com"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceRepository_virtualFormats = `
+resource "artifactory_virtual_repository" "alpine" {
+	key          = "acctest-data-virtual-alpine"
+	package_type = "alpine"
+
+	alpine {
+		primary_keypair_ref = "acctest-keypair"
+	}
+}
+
+resource "artifactory_virtual_repository" "go" {
+	key          = "acctest-data-virtual-go"
+	package_type = "go"
+
+	go {
+		external_dependencies_enabled  = true
+		external_dependencies_patterns = [ "**/github.com/**" ]
+	}
+}
+
+resource "artifactory_virtual_repository" "helm" {
+	key          = "acctest-data-virtual-helm"
+	package_type = "helm"
+
+	helm {
+		retrieval_cache_period_seconds = 300
+	}
+}
+
+data "artifactory_repository" "alpine" {
+	key = "${artifactory_virtual_repository.alpine.key}"
+}
+
+data "artifactory_repository" "go" {
+	key = "${artifactory_virtual_repository.go.key}"
+}
+
+data "artifactory_repository" "helm" {
+	key = "${artifactory_virtual_repository.helm.key}"
+}`
+
+func TestAccDataSourceRepository_virtualFormats(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy("artifactory_virtual_repository.helm"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccDataSourceRepository_virtualFormats,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.artifactory_repository.alpine", "alpine.0.primary_keypair_ref", "acctest-keypair"),
+					resource.TestCheckResourceAttr("data.artifactory_repository.go", "go.0.external_dependencies_enabled", "true"),
+					resource.TestCheckResourceAttr("data.artifactory_repository.go", "go.0.external_dependencies_patterns.#", "1"),
+					resource.TestCheckResourceAttr("data.artifactory_repository.go", "go.0.external_dependencies_patterns.0", "**/github.com/**"),
+					resource.TestCheckResourceAttr("data.artifactory_repository.helm", "helm.0.retrieval_cache_period_seconds", "300"),
+				),
+			},
+		},
+	})
+}
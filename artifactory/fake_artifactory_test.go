@@ -0,0 +1,1237 @@
+package artifactory
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeArtifactory is an in-process stand-in for the parts of the Artifactory
+// REST API used by the provider, so the acceptance tests can run without a
+// licensed instance. Objects are kept as decoded JSON so that any attribute
+// the provider sends is returned on read.
+type fakeArtifactory struct {
+	*httptest.Server
+
+	username    string
+	password    string
+	apiKey      string
+	accessToken string
+
+	mu           sync.Mutex
+	latency      time.Duration
+	faults       []*fakeFault
+	inFlight     int // requests being served
+	maxInFlight  int // highest number of requests served at the same time since reset
+	userLookups  int // group requests including the names of the users since reset
+	repositories map[string]map[string]interface{}
+	users        map[string]map[string]interface{}
+	expired      map[string]bool       // users whose password has been expired
+	apiKeys      map[string]string     // API keys by user
+	tokens       map[string]*fakeToken // access tokens issued, by token
+	propertySets map[string]map[string]interface{}
+	repoLayouts  map[string]map[string]interface{}
+	lastKey      int // sequence number of the last API key created
+	groups       map[string]map[string]interface{}
+	permissions  map[string]map[string]interface{}
+	replications map[string][]map[string]interface{}
+}
+
+// fakeFault alters the response to matching requests
+type fakeFault struct {
+	method string        // HTTP method to match, empty for any
+	path   string        // prefix of the path below /api/ to match
+	status int           // status to answer with, 0 to serve the request normally
+	delay  time.Duration // time to wait before answering
+	times  int           // number of requests affected, 0 for every request
+
+	retryAfter string // Retry-After header sent with the status
+	lost       bool   // serve the request, then answer with the status as a proxy losing the answer would
+}
+
+var fakeRepositoryKey = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// fakeRepoLayouts are the repository layouts shipped with Artifactory
+var fakeRepoLayouts = map[string]bool{
+	"bower-default": true, "build-default": true, "composer-default": true, "conan-default": true,
+	"go-default": true, "ivy-default": true, "maven-1-default": true, "maven-2-default": true,
+	"npm-default": true, "nuget-default": true, "puppet-default": true, "sbt-default": true,
+	"simple-default": true, "vcs-default": true,
+}
+
+// fakeRepositoryDefaults are applied by Artifactory to attributes missing from a create request
+var fakeRepositoryDefaults = map[string]map[string]interface{}{
+	"local": {
+		"includesPattern":         "**/*",
+		"excludesPattern":         "",
+		"handleReleases":          true,
+		"handleSnapshots":         true,
+		"checksumPolicyType":      "client-checksums",
+		"snapshotVersionBehavior": "unique",
+		"dockerApiVersion":        "V2",
+	},
+	"remote": {
+		"includesPattern":                "**/*",
+		"excludesPattern":                "",
+		"handleReleases":                 true,
+		"handleSnapshots":                true,
+		"storeArtifactsLocally":          true,
+		"socketTimeoutMillis":            15000,
+		"retrievalCachePeriodSecs":       7200,
+		"missedRetrievalCachePeriodSecs": 1800,
+	},
+	"virtual": {
+		"includesPattern":                      "**/*",
+		"excludesPattern":                      "",
+		"pomRepositoryReferencesCleanupPolicy": "discard_active_reference",
+		"repositories":                         []interface{}{},
+	},
+}
+
+// fakeDefaultRepoLayouts are the layouts Artifactory gives new repositories
+// of a package type, other types getting simple-default
+var fakeDefaultRepoLayouts = map[string]string{
+	"maven": "maven-2-default", "gradle": "maven-2-default", "ivy": "ivy-default", "sbt": "sbt-default",
+	"npm": "npm-default", "bower": "bower-default", "nuget": "nuget-default", "composer": "composer-default",
+	"conan": "conan-default", "go": "go-default", "puppet": "puppet-default",
+}
+
+// fakePackageTypeAttributes are the repository attributes Artifactory only
+// keeps for some package types
+var fakePackageTypeAttributes = map[string][]string{
+	"maxUniqueSnapshots":                   {"maven", "gradle", "ivy", "sbt"},
+	"snapshotVersionBehavior":              {"maven", "gradle", "ivy", "sbt"},
+	"suppressPomConsistencyChecks":         {"maven", "gradle", "ivy", "sbt"},
+	"pomRepositoryReferencesCleanupPolicy": {"maven", "gradle", "ivy", "sbt"},
+	"maxUniqueTags":                        {"docker"},
+	"dockerApiVersion":                     {"docker"},
+	"calculateYumMetadata":                 {"rpm"},
+	"yumRootDepth":                         {"rpm"},
+}
+
+// newFakeArtifactory starts a fake Artifactory accepting the given credentials,
+// as well as a fixed API key and access token.
+// Like a fresh instance, it contains the auto-join readers group.
+func newFakeArtifactory(username, password string) *fakeArtifactory {
+	f := &fakeArtifactory{
+		username:     username,
+		password:     password,
+		apiKey:       "AKCfakeapikey",
+		accessToken:  "eyJfakeaccesstoken",
+		repositories: make(map[string]map[string]interface{}),
+		users:        make(map[string]map[string]interface{}),
+		expired:      make(map[string]bool),
+		apiKeys:      make(map[string]string),
+		tokens:       make(map[string]*fakeToken),
+		propertySets: make(map[string]map[string]interface{}),
+		repoLayouts:  make(map[string]map[string]interface{}),
+		groups:       make(map[string]map[string]interface{}),
+		permissions:  make(map[string]map[string]interface{}),
+		replications: make(map[string][]map[string]interface{}),
+	}
+	f.groups["readers"] = map[string]interface{}{
+		"name":            "readers",
+		"description":     "A group for read-only users",
+		"autoJoin":        true,
+		"adminPrivileges": false,
+	}
+	f.propertySets["artifactory"] = map[string]interface{}{"visible": true}
+	for name := range fakeRepoLayouts {
+		f.repoLayouts[name] = map[string]interface{}{
+			"artifactPathPattern": "[orgPath]/[module]/[baseRev]/[module]-[baseRev](-[classifier]).[ext]",
+		}
+	}
+	f.Server = httptest.NewServer(f)
+	return f
+}
+
+// setLatency delays every response by d
+func (f *fakeArtifactory) setLatency(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = d
+}
+
+// fail answers the next times requests matching method and path with status
+func (f *fakeArtifactory) fail(method, path string, status, times int) {
+	f.addFault(&fakeFault{method: method, path: path, status: status, times: times})
+}
+
+// addFault registers a fault, which applies until it has matched its number of requests
+func (f *fakeArtifactory) addFault(ft *fakeFault) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = append(f.faults, ft)
+}
+
+// reset removes all faults and latency
+func (f *fakeArtifactory) reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = nil
+	f.latency = 0
+	f.maxInFlight = 0
+	f.userLookups = 0
+}
+
+// peakConcurrency returns the highest number of requests served at the same time since reset
+func (f *fakeArtifactory) peakConcurrency() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.maxInFlight
+}
+
+// groupUserLookups returns the number of group requests including the names of the
+// users since reset
+func (f *fakeArtifactory) groupUserLookups() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.userLookups
+}
+
+// removeOutOfBand deletes an object behind the provider's back, so that it is
+// answered with a 404 from then on. path is relative to /api/, for example
+// "repositories/libs-release-local".
+func (f *fakeArtifactory) removeOutOfBand(path string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	kind, name := f.route(path)
+	switch kind {
+	case "repositories":
+		delete(f.repositories, name)
+		delete(f.replications, name)
+	case "users":
+		delete(f.users, name)
+		delete(f.expired, name)
+		delete(f.apiKeys, name)
+	case "api-key":
+		delete(f.apiKeys, name)
+	case "groups":
+		delete(f.groups, name)
+	case "permissions":
+		delete(f.permissions, name)
+	case "replications", "multiple-replications":
+		delete(f.replications, name)
+	}
+}
+
+// updateOutOfBand changes attributes of an object behind the provider's back.
+// path is relative to /api/, as for removeOutOfBand.
+func (f *fakeArtifactory) updateOutOfBand(path string, attrs map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var obj map[string]interface{}
+	kind, name := f.route(path)
+	switch kind {
+	case "repositories":
+		obj = f.repositories[name]
+	case "users":
+		obj = f.users[name]
+	case "groups":
+		obj = f.groups[name]
+	case "permissions":
+		obj = f.permissions[name]
+	}
+	for k, v := range attrs {
+		obj[k] = v
+	}
+}
+
+// route splits a path below /api/ into the kind of object and its name
+func (f *fakeArtifactory) route(path string) (kind, name string) {
+	prefixes := []struct{ prefix, kind string }{
+		{"system/ping", "ping"},
+		{"system/configuration", "configuration"},
+		{"repositories/", "repositories"},
+		{"repositories", "repository-list"},
+		{"security/users/authorization/expirePassword/", "expire-password"},
+		{"security/users/", "users"},
+		{"security/apiKey/", "api-key"},
+		{"security/token/revoke", "revoke-token"},
+		{"security/token", "token"},
+		{"security/apiKey", "api-key"},
+		{"security/groups/", "groups"},
+		{"security/permissions/", "permissions"},
+		{"replications/multiple/", "multiple-replications"},
+		{"replications/", "replications"},
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(path, p.prefix) {
+			return p.kind, strings.TrimPrefix(path, p.prefix)
+		}
+	}
+	return "", ""
+}
+
+// takeFault returns the fault for the request, if any, and the configured latency
+func (f *fakeArtifactory) takeFault(method, path string) (*fakeFault, time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, ft := range f.faults {
+		if (ft.method == "" || ft.method == method) && strings.HasPrefix(path, ft.path) {
+			if ft.times > 0 {
+				ft.times--
+				if ft.times == 0 {
+					f.faults = append(f.faults[:i], f.faults[i+1:]...)
+				}
+			}
+			return ft, f.latency
+		}
+	}
+	return nil, f.latency
+}
+
+func (f *fakeArtifactory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/")
+
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.maxInFlight {
+		f.maxInFlight = f.inFlight
+	}
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	ft, latency := f.takeFault(r.Method, path)
+	time.Sleep(latency)
+	if ft != nil {
+		time.Sleep(ft.delay)
+		if ft.status != 0 && ft.lost {
+			status, proxied := ft.status, w
+			w = httptest.NewRecorder()
+			defer fakeError(proxied, status, http.StatusText(status))
+		} else if ft.status != 0 {
+			if ft.retryAfter != "" {
+				w.Header().Set("Retry-After", ft.retryAfter)
+			}
+			fakeError(w, ft.status, http.StatusText(ft.status))
+			return
+		}
+	}
+
+	user, ok := f.authenticate(r)
+	if !ok {
+		fakeError(w, http.StatusUnauthorized, "Bad credentials")
+		return
+	}
+
+	var body map[string]interface{}
+	if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+		r.ParseForm()
+	} else if r.Method == "PUT" || r.Method == "POST" || r.Method == "PATCH" {
+		// an empty body is fine for actions such as expiring a password
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err.Error() != "EOF" {
+			fakeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %s", err))
+			return
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	kind, name := f.route(path)
+	switch kind {
+	case "ping":
+		w.Write([]byte("OK"))
+	case "configuration":
+		f.serveConfiguration(w, r.Method, body)
+	case "repository-list":
+		f.serveRepositoryList(w, r)
+	case "repositories":
+		f.serveRepository(w, r.Method, name, body)
+	case "users":
+		f.serveUser(w, r.Method, name, body)
+	case "expire-password":
+		f.serveExpirePassword(w, r.Method, name)
+	case "api-key":
+		f.serveAPIKey(w, r.Method, user, name)
+	case "token":
+		f.serveToken(w, r.Method, user, r.PostForm)
+	case "revoke-token":
+		f.serveRevokeToken(w, r.Method, r.PostForm)
+	case "groups":
+		f.serveGroup(w, r.Method, name, r.URL.Query().Get("includeUsers") == "true", body)
+	case "permissions":
+		f.servePermission(w, r.Method, name, body)
+	case "replications", "multiple-replications":
+		f.serveReplication(w, r.Method, name, kind == "multiple-replications", body)
+	default:
+		fakeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// authenticate accepts basic authentication, an API key or an access token,
+// and returns the name of the authenticated user. Besides the admin, users can
+// authenticate with their password, unless it has expired, or their API key.
+func (f *fakeArtifactory) authenticate(r *http.Request) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if key := r.Header.Get("X-JFrog-Art-Api"); key != "" {
+		if key == f.apiKey {
+			return f.username, true
+		}
+		for name, k := range f.apiKeys {
+			if key == k {
+				return name, true
+			}
+		}
+		return "", false
+	}
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if t, ok := f.tokens[token]; ok {
+			return t.username, t.expiry.IsZero() || time.Now().Before(t.expiry)
+		}
+		return f.username, token == f.accessToken
+	}
+	name, pass, ok := r.BasicAuth()
+	if !ok {
+		return "", false
+	}
+	if name == f.username {
+		return name, pass == f.password
+	}
+	user, exists := f.users[name]
+	return name, exists && !f.expired[name] && pass != "" && user["password"] == pass
+}
+
+// isAdmin reports whether the user has admin privileges
+func (f *fakeArtifactory) isAdmin(name string) bool {
+	if name == f.username {
+		return true
+	}
+	admin, _ := f.users[name]["admin"].(bool)
+	return admin
+}
+
+func (f *fakeArtifactory) serveRepositoryList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		fakeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	repoType := r.URL.Query().Get("type")
+	packageType := r.URL.Query().Get("packageType")
+
+	keys := make([]string, 0, len(f.repositories))
+	for key := range f.repositories {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	list := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		repo := f.repositories[key]
+		rclass, _ := repo["rclass"].(string)
+		pt, _ := repo["packageType"].(string)
+		if (repoType != "" && !strings.EqualFold(repoType, rclass)) || (packageType != "" && !strings.EqualFold(packageType, pt)) {
+			continue
+		}
+		list = append(list, map[string]interface{}{
+			"key":         key,
+			"type":        strings.ToUpper(rclass),
+			"description": repo["description"],
+			"url":         fmt.Sprintf("%s/%s", f.URL, key),
+			"packageType": strings.Title(pt),
+		})
+	}
+
+	fakeJSON(w, http.StatusOK, list)
+}
+
+func (f *fakeArtifactory) serveRepository(w http.ResponseWriter, method, key string, body map[string]interface{}) {
+	repo, exists := f.repositories[key]
+
+	switch method {
+	case "GET":
+		if !exists {
+			fakeError(w, http.StatusNotFound, fmt.Sprintf("Repository %s does not exist", key))
+			return
+		}
+		// the password of remote repositories is only returned encrypted
+		if password, _ := repo["password"].(string); password != "" {
+			encrypted := make(map[string]interface{}, len(repo))
+			for k, v := range repo {
+				encrypted[k] = v
+			}
+			encrypted["password"] = "AM.fake" + base64.StdEncoding.EncodeToString([]byte(password))
+			repo = encrypted
+		}
+		fakeJSON(w, http.StatusOK, repo)
+	case "PUT":
+		if !fakeRepositoryKey.MatchString(key) {
+			fakeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid repository key '%s'", key))
+			return
+		}
+		for existing := range f.repositories {
+			if strings.EqualFold(existing, key) {
+				fakeError(w, http.StatusBadRequest, "Case insensitive repository key already exists")
+				return
+			}
+		}
+		rclass, _ := body["rclass"].(string)
+		defaults, ok := fakeRepositoryDefaults[rclass]
+		if !ok {
+			fakeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid repository type '%s'", rclass))
+			return
+		}
+		repo = map[string]interface{}{"packageType": "generic", "description": "", "notes": ""}
+		for k, v := range defaults {
+			repo[k] = v
+		}
+		if _, ok := body["repoLayoutRef"]; !ok {
+			pt, _ := body["packageType"].(string)
+			repo["repoLayoutRef"] = "simple-default"
+			if layout, ok := fakeDefaultRepoLayouts[strings.ToLower(pt)]; ok {
+				repo["repoLayoutRef"] = layout
+			}
+		}
+		if err := f.mergeRepository(repo, body); err != nil {
+			fakeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		repo["key"] = key
+		f.repositories[key] = repo
+		w.Write([]byte(fmt.Sprintf("Successfully created repository '%s' \n", key)))
+	case "POST":
+		if !exists {
+			fakeError(w, http.StatusNotFound, fmt.Sprintf("Repository %s does not exist", key))
+			return
+		}
+		if rclass, ok := body["rclass"]; ok && rclass != repo["rclass"] {
+			fakeError(w, http.StatusBadRequest, "Repository type cannot be changed")
+			return
+		}
+		updated := make(map[string]interface{}, len(repo))
+		for k, v := range repo {
+			updated[k] = v
+		}
+		if err := f.mergeRepository(updated, body); err != nil {
+			fakeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		updated["key"] = key
+		f.repositories[key] = updated
+		w.Write([]byte(fmt.Sprintf("Repository %s update successfully.\n", key)))
+	case "DELETE":
+		if !exists {
+			fakeError(w, http.StatusNotFound, fmt.Sprintf("Repository %s does not exist", key))
+			return
+		}
+		delete(f.repositories, key)
+		delete(f.replications, key)
+		w.Write([]byte(fmt.Sprintf("Repository '%s' and all its content have been removed successfully.\n", key)))
+	default:
+		fakeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// mergeRepository applies the attributes of a create or update request the way Artifactory does
+func (f *fakeArtifactory) mergeRepository(repo, body map[string]interface{}) error {
+	for k, v := range body {
+		repo[k] = v
+	}
+
+	packageType, _ := repo["packageType"].(string)
+	for k, types := range fakePackageTypeAttributes {
+		applies := false
+		for _, t := range types {
+			applies = applies || strings.EqualFold(t, packageType)
+		}
+		if !applies {
+			delete(repo, k)
+		}
+	}
+
+	if repo["rclass"] == "remote" {
+		// Artifactory marks the description of remote repositories
+		if desc, _ := repo["description"].(string); !strings.HasSuffix(desc, "(local file cache)") {
+			repo["description"] = strings.TrimSpace(desc + " (local file cache)")
+		}
+	}
+
+	if layout, ok := repo["repoLayoutRef"].(string); ok && layout != "" && f.repoLayouts[layout] == nil {
+		return fmt.Errorf("Repo layout reference %s does not exist", layout)
+	}
+
+	if sets, ok := repo["propertySets"].([]interface{}); ok {
+		for _, ps := range sets {
+			if f.propertySets[ps.(string)] == nil {
+				return fmt.Errorf("Property set %s does not exist", ps)
+			}
+		}
+	}
+
+	if members, ok := repo["repositories"].([]interface{}); ok {
+		for _, m := range members {
+			if _, ok := f.repositories[m.(string)]; !ok {
+				return fmt.Errorf("Repository '%s' does not exist", m)
+			}
+		}
+	}
+
+	return nil
+}
+
+// setUserGroups changes the groups of a user behind Terraform's back
+func (f *fakeArtifactory) setUserGroups(name string, groups ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	l := make([]interface{}, 0, len(groups))
+	for _, g := range groups {
+		l = append(l, g)
+	}
+	f.users[name]["groups"] = l
+}
+
+// userGroups returns the groups of a user
+func (f *fakeArtifactory) userGroups(name string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	groups := make([]string, 0)
+	for _, g := range f.users[name]["groups"].([]interface{}) {
+		groups = append(groups, g.(string))
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// repositoryPassword returns the password of a remote repository
+func (f *fakeArtifactory) repositoryPassword(key string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	password, _ := f.repositories[key]["password"].(string)
+	return password
+}
+
+// replicationPassword returns the password the fake stores for a push replication target
+func (f *fakeArtifactory) replicationPassword(key, url string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.replications[key] {
+		if r["url"] == url {
+			password, _ := r["password"].(string)
+			return password
+		}
+	}
+	return ""
+}
+
+// userPassword returns the password of a user and whether it has been expired
+func (f *fakeArtifactory) userPassword(name string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	password, _ := f.users[name]["password"].(string)
+	return password, f.expired[name]
+}
+
+func (f *fakeArtifactory) serveUser(w http.ResponseWriter, method, name string, body map[string]interface{}) {
+	user, exists := f.users[name]
+
+	switch method {
+	case "GET":
+		if !exists {
+			fakeError(w, http.StatusNotFound, fmt.Sprintf("User '%s' not found", name))
+			return
+		}
+		// the password is never returned
+		result := make(map[string]interface{}, len(user))
+		for k, v := range user {
+			if k != "password" {
+				result[k] = v
+			}
+		}
+		fakeJSON(w, http.StatusOK, result)
+	case "PUT":
+		if email, _ := body["email"].(string); email == "" {
+			fakeError(w, http.StatusBadRequest, "Email is required")
+			return
+		}
+		if password, _ := body["password"].(string); password == "" {
+			fakeError(w, http.StatusBadRequest, "Password is required")
+			return
+		}
+		user = map[string]interface{}{
+			"admin":                    false,
+			"profileUpdatable":         true,
+			"internalPasswordDisabled": false,
+			"realm":                    "internal",
+		}
+		for k, v := range body {
+			user[k] = v
+		}
+		user["name"] = name
+		if groups, _ := user["groups"].([]interface{}); len(groups) == 0 {
+			user["groups"] = f.autoJoinGroups()
+		}
+		f.users[name] = user
+		delete(f.expired, name)
+		w.WriteHeader(http.StatusCreated)
+	case "POST":
+		if !exists {
+			fakeError(w, http.StatusNotFound, fmt.Sprintf("User '%s' not found", name))
+			return
+		}
+		for k, v := range body {
+			// null keeps the current value, such as the groups
+			if v != nil {
+				user[k] = v
+			}
+		}
+		user["name"] = name
+		if _, ok := body["password"]; ok {
+			delete(f.expired, name)
+		}
+		w.WriteHeader(http.StatusOK)
+	case "DELETE":
+		if !exists {
+			fakeError(w, http.StatusNotFound, fmt.Sprintf("User '%s' not found", name))
+			return
+		}
+		delete(f.users, name)
+		delete(f.expired, name)
+		w.Write([]byte(fmt.Sprintf("User '%s' has been removed successfully.\n", name)))
+	default:
+		fakeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// autoJoinGroups returns the groups new users are added to when none are given
+func (f *fakeArtifactory) autoJoinGroups() []interface{} {
+	groups := make([]interface{}, 0)
+	for name, g := range f.groups {
+		if g["autoJoin"] == true {
+			groups = append(groups, name)
+		}
+	}
+	return groups
+}
+
+func (f *fakeArtifactory) serveExpirePassword(w http.ResponseWriter, method, name string) {
+	if method != "POST" {
+		fakeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	if _, exists := f.users[name]; !exists {
+		fakeError(w, http.StatusNotFound, fmt.Sprintf("User '%s' not found", name))
+		return
+	}
+	f.expired[name] = true
+	w.WriteHeader(http.StatusOK)
+}
+
+// serveAPIKey manages the API key of the authenticated user, or revokes the
+// API key of another user for an admin
+func (f *fakeArtifactory) serveAPIKey(w http.ResponseWriter, method, user, name string) {
+	if name != "" {
+		if method != "DELETE" {
+			fakeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			return
+		}
+		if !f.isAdmin(user) {
+			fakeError(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+		delete(f.apiKeys, name)
+		fakeJSON(w, http.StatusOK, map[string]interface{}{"info": "API key revoked"})
+		return
+	}
+
+	key, exists := f.apiKeys[user]
+
+	switch method {
+	case "GET":
+		if !exists {
+			fakeJSON(w, http.StatusOK, map[string]interface{}{})
+			return
+		}
+		fakeJSON(w, http.StatusOK, map[string]interface{}{"apiKey": key})
+	case "POST", "PUT":
+		if method == "POST" && exists {
+			fakeError(w, http.StatusBadRequest, "Api key already exists")
+			return
+		}
+		if method == "PUT" && !exists {
+			fakeError(w, http.StatusNotFound, "Api key not found")
+			return
+		}
+		f.lastKey++
+		f.apiKeys[user] = fmt.Sprintf("AKCp5fake%06d", f.lastKey)
+		status := http.StatusCreated
+		if method == "PUT" {
+			status = http.StatusOK
+		}
+		fakeJSON(w, status, map[string]interface{}{"apiKey": f.apiKeys[user]})
+	case "DELETE":
+		delete(f.apiKeys, user)
+		fakeJSON(w, http.StatusOK, map[string]interface{}{"info": "API key revoked"})
+	default:
+		fakeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// apiKeyOf returns the API key of a user, if any
+func (f *fakeArtifactory) apiKeyOf(name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.apiKeys[name]
+}
+
+// serveConfiguration answers with the property sets and repository layouts of
+// the system configuration as XML, and merges patches into them. Patches are
+// expected to be JSON, the subset of YAML sent by the client.
+func (f *fakeArtifactory) serveConfiguration(w http.ResponseWriter, method string, body map[string]interface{}) {
+	switch method {
+	case "GET":
+		w.Header().Set("Content-Type", "application/xml")
+		xml.NewEncoder(w).Encode(f.configurationXML())
+	case "PATCH":
+		sets, _ := body["propertySets"].(map[string]interface{})
+		layouts, _ := body["repoLayouts"].(map[string]interface{})
+		for name, l := range layouts {
+			if l == nil {
+				continue
+			}
+			merged := fakeMerge(f.repoLayouts[name], l.(map[string]interface{}))
+			if pattern, _ := merged["artifactPathPattern"].(string); pattern == "" {
+				fakeError(w, http.StatusBadRequest, fmt.Sprintf("Repo layout %s must have an artifact path pattern", name))
+				return
+			}
+		}
+
+		for name, ps := range sets {
+			if ps == nil {
+				delete(f.propertySets, name)
+				continue
+			}
+			f.propertySets[name] = fakeMerge(f.propertySets[name], ps.(map[string]interface{}))
+		}
+		for name, l := range layouts {
+			if l == nil {
+				delete(f.repoLayouts, name)
+				continue
+			}
+			f.repoLayouts[name] = fakeMerge(f.repoLayouts[name], l.(map[string]interface{}))
+		}
+		w.Write([]byte(fmt.Sprintf("%d changes to config merged successfully", len(sets)+len(layouts))))
+	default:
+		fakeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// fakeMerge returns a copy of dst with patch merged in, removing the entries
+// set to nil in patch
+func fakeMerge(dst, patch map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(dst)+len(patch))
+	for k, v := range dst {
+		result[k] = v
+	}
+	for k, v := range patch {
+		switch v := v.(type) {
+		case nil:
+			delete(result, k)
+		case map[string]interface{}:
+			existing, _ := result[k].(map[string]interface{})
+			result[k] = fakeMerge(existing, v)
+		default:
+			result[k] = v
+		}
+	}
+	return result
+}
+
+type fakeConfigXML struct {
+	XMLName      xml.Name             `xml:"config"`
+	PropertySets []fakePropertySetXML `xml:"propertySets>propertySet"`
+	RepoLayouts  []fakeRepoLayoutXML  `xml:"repoLayouts>repoLayout"`
+}
+
+type fakePropertySetXML struct {
+	Name       string            `xml:"name"`
+	Visible    bool              `xml:"visible"`
+	Properties []fakePropertyXML `xml:"properties>property"`
+}
+
+type fakePropertyXML struct {
+	Name                   string                   `xml:"name"`
+	PredefinedValues       []fakePredefinedValueXML `xml:"predefinedValues>predefinedValue"`
+	ClosedPredefinedValues bool                     `xml:"closedPredefinedValues"`
+	MultipleChoice         bool                     `xml:"multipleChoice"`
+}
+
+type fakePredefinedValueXML struct {
+	Value        string `xml:"value"`
+	DefaultValue bool   `xml:"defaultValue"`
+}
+
+type fakeRepoLayoutXML struct {
+	Name                             string `xml:"name"`
+	ArtifactPathPattern              string `xml:"artifactPathPattern"`
+	DistinctiveDescriptorPathPattern bool   `xml:"distinctiveDescriptorPathPattern"`
+	DescriptorPathPattern            string `xml:"descriptorPathPattern,omitempty"`
+	FolderIntegrationRevisionRegExp  string `xml:"folderIntegrationRevisionRegExp,omitempty"`
+	FileIntegrationRevisionRegExp    string `xml:"fileIntegrationRevisionRegExp,omitempty"`
+}
+
+func (f *fakeArtifactory) configurationXML() *fakeConfigXML {
+	config := &fakeConfigXML{}
+
+	for _, name := range fakeSortedKeys(f.propertySets) {
+		ps := f.propertySets[name]
+		set := fakePropertySetXML{Name: name}
+		set.Visible, _ = ps["visible"].(bool)
+		properties, _ := ps["properties"].(map[string]interface{})
+		for _, pname := range fakeSortedKeys(properties) {
+			p := properties[pname].(map[string]interface{})
+			prop := fakePropertyXML{Name: pname}
+			prop.ClosedPredefinedValues, _ = p["closedPredefinedValues"].(bool)
+			prop.MultipleChoice, _ = p["multipleChoice"].(bool)
+			values, _ := p["predefinedValues"].(map[string]interface{})
+			for _, value := range fakeSortedKeys(values) {
+				v := values[value].(map[string]interface{})
+				def, _ := v["defaultValue"].(bool)
+				prop.PredefinedValues = append(prop.PredefinedValues, fakePredefinedValueXML{value, def})
+			}
+			set.Properties = append(set.Properties, prop)
+		}
+		config.PropertySets = append(config.PropertySets, set)
+	}
+
+	for _, name := range fakeSortedKeys(f.repoLayouts) {
+		l := f.repoLayouts[name]
+		layout := fakeRepoLayoutXML{Name: name}
+		layout.ArtifactPathPattern, _ = l["artifactPathPattern"].(string)
+		layout.DistinctiveDescriptorPathPattern, _ = l["distinctiveDescriptorPathPattern"].(bool)
+		layout.DescriptorPathPattern, _ = l["descriptorPathPattern"].(string)
+		layout.FolderIntegrationRevisionRegExp, _ = l["folderIntegrationRevisionRegExp"].(string)
+		layout.FileIntegrationRevisionRegExp, _ = l["fileIntegrationRevisionRegExp"].(string)
+		config.RepoLayouts = append(config.RepoLayouts, layout)
+	}
+
+	return config
+}
+
+// fakeSortedKeys returns the keys of a map of objects in order
+func fakeSortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]interface{}:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]map[string]interface{}:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fakeToken is an access token issued by the fake
+type fakeToken struct {
+	id       string
+	username string
+	scope    string
+	expiry   time.Time // zero for a token that does not expire
+}
+
+var fakeTokenScope = regexp.MustCompile(`^(member-of-groups:(\*|[^,\s]+(,[^,\s]+)*)|api:\*)$`)
+
+func (f *fakeArtifactory) serveToken(w http.ResponseWriter, method, user string, form url.Values) {
+	if method != "POST" {
+		fakeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	username := form.Get("username")
+	if username == "" {
+		username = user
+	}
+	if username != user && !f.isAdmin(user) {
+		fakeError(w, http.StatusForbidden, "Only an admin can create tokens for other users")
+		return
+	}
+
+	scope := form.Get("scope")
+	if scope == "" {
+		scope = "member-of-groups:*"
+	}
+	if !fakeTokenScope.MatchString(scope) {
+		fakeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid scope: %s", scope))
+		return
+	}
+
+	expiresIn := 3600
+	if v := form.Get("expires_in"); v != "" {
+		var err error
+		if expiresIn, err = strconv.Atoi(v); err != nil || expiresIn < 0 {
+			fakeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid expires_in: %s", v))
+			return
+		}
+	}
+
+	f.lastKey++
+	t := &fakeToken{id: fmt.Sprintf("fake-token-%06d", f.lastKey), username: username, scope: scope}
+	if expiresIn > 0 {
+		t.expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+
+	claims, _ := json.Marshal(map[string]interface{}{
+		"sub": "jfrt@fake/users/" + username,
+		"scp": scope,
+		"aud": form.Get("audience"),
+		"jti": t.id,
+	})
+	token := "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(claims) + ".fakesignature"
+	f.tokens[token] = t
+
+	result := map[string]interface{}{
+		"access_token": token,
+		"expires_in":   expiresIn,
+		"scope":        scope,
+		"token_type":   "Bearer",
+	}
+	if form.Get("refreshable") == "true" {
+		result["refresh_token"] = fmt.Sprintf("fake-refresh-%06d", f.lastKey)
+	}
+	fakeJSON(w, http.StatusOK, result)
+}
+
+func (f *fakeArtifactory) serveRevokeToken(w http.ResponseWriter, method string, form url.Values) {
+	if method != "POST" {
+		fakeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	if _, ok := f.tokens[form.Get("token")]; !ok {
+		fakeError(w, http.StatusNotFound, "Token not found")
+		return
+	}
+	delete(f.tokens, form.Get("token"))
+	w.Write([]byte("Token revoked"))
+}
+
+// accessTokens returns the scopes of the tokens issued for a user and not revoked
+func (f *fakeArtifactory) accessTokens(username string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	scopes := make([]string, 0)
+	for _, t := range f.tokens {
+		if t.username == username {
+			scopes = append(scopes, t.scope)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// groupMembers returns the sorted names of the users in a group
+func (f *fakeArtifactory) groupMembers(group string) []string {
+	members := make([]string, 0)
+	for name, user := range f.users {
+		groups, _ := user["groups"].([]interface{})
+		for _, g := range groups {
+			if g == group {
+				members = append(members, name)
+			}
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+func (f *fakeArtifactory) serveGroup(w http.ResponseWriter, method, name string, includeUsers bool, body map[string]interface{}) {
+	group, exists := f.groups[name]
+
+	switch method {
+	case "GET":
+		if !exists {
+			fakeError(w, http.StatusNotFound, fmt.Sprintf("Group '%s' not found", name))
+			return
+		}
+		if !includeUsers {
+			fakeJSON(w, http.StatusOK, group)
+			return
+		}
+		f.userLookups++
+		result := map[string]interface{}{"userNames": f.groupMembers(name)}
+		for k, v := range group {
+			result[k] = v
+		}
+		fakeJSON(w, http.StatusOK, result)
+	case "PUT":
+		group = map[string]interface{}{"autoJoin": false, "adminPrivileges": false}
+		for k, v := range body {
+			group[k] = v
+		}
+		group["name"] = name
+		f.groups[name] = group
+		w.WriteHeader(http.StatusCreated)
+	case "POST":
+		if !exists {
+			fakeError(w, http.StatusNotFound, fmt.Sprintf("Group '%s' not found", name))
+			return
+		}
+		for k, v := range body {
+			group[k] = v
+		}
+		group["name"] = name
+		w.WriteHeader(http.StatusOK)
+	case "DELETE":
+		if !exists {
+			fakeError(w, http.StatusNotFound, fmt.Sprintf("Group '%s' not found", name))
+			return
+		}
+		delete(f.groups, name)
+		w.Write([]byte(fmt.Sprintf("Group '%s' has been removed successfully.\n", name)))
+	default:
+		fakeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+func (f *fakeArtifactory) servePermission(w http.ResponseWriter, method, name string, body map[string]interface{}) {
+	perm, exists := f.permissions[name]
+
+	switch method {
+	case "GET":
+		if !exists {
+			fakeError(w, http.StatusNotFound, fmt.Sprintf("Permission target '%s' not found", name))
+			return
+		}
+		fakeJSON(w, http.StatusOK, perm)
+	case "PUT":
+		perm = map[string]interface{}{"includesPattern": "**", "excludesPattern": ""}
+		for k, v := range body {
+			perm[k] = v
+		}
+		perm["name"] = name
+		f.permissions[name] = perm
+		if exists {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case "DELETE":
+		if !exists {
+			fakeError(w, http.StatusNotFound, fmt.Sprintf("Permission target '%s' not found", name))
+			return
+		}
+		delete(f.permissions, name)
+		w.Write([]byte(fmt.Sprintf("Permission Target '%s' has been removed successfully.\n", name)))
+	default:
+		fakeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+func (f *fakeArtifactory) serveReplication(w http.ResponseWriter, method, key string, multiple bool, body map[string]interface{}) {
+	replications, exists := f.replications[key]
+
+	if method == "GET" || method == "DELETE" {
+		if !exists {
+			fakeError(w, http.StatusNotFound, fmt.Sprintf("Could not find replication for repository '%s'", key))
+			return
+		}
+		if method == "GET" {
+			// the passwords of the targets are only returned encrypted
+			encrypted := make([]map[string]interface{}, 0, len(replications))
+			for _, r := range replications {
+				e := make(map[string]interface{}, len(r))
+				for k, v := range r {
+					e[k] = v
+				}
+				if password, _ := r["password"].(string); password != "" {
+					e["password"] = "AM.fake" + base64.StdEncoding.EncodeToString([]byte(password))
+				}
+				encrypted = append(encrypted, e)
+			}
+			fakeJSON(w, http.StatusOK, encrypted)
+		} else {
+			delete(f.replications, key)
+			w.WriteHeader(http.StatusOK)
+		}
+		return
+	}
+
+	if method != "PUT" && method != "POST" {
+		fakeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	if _, ok := f.repositories[key]; !ok {
+		fakeError(w, http.StatusBadRequest, fmt.Sprintf("Could not find repository '%s'", key))
+		return
+	}
+	if method == "POST" && !exists {
+		fakeError(w, http.StatusNotFound, fmt.Sprintf("Could not find replication for repository '%s'", key))
+		return
+	}
+
+	if multiple {
+		// a target sent without a password keeps its current one
+		passwords := make(map[string]interface{})
+		for _, r := range replications {
+			passwords[r["url"].(string)] = r["password"]
+		}
+
+		members, _ := body["replications"].([]interface{})
+		replications = make([]map[string]interface{}, 0, len(members))
+		for _, m := range members {
+			r := m.(map[string]interface{})
+			if _, ok := r["password"]; !ok && method == "POST" {
+				r["password"] = passwords[r["url"].(string)]
+			}
+			r["cronExp"] = body["cronExp"]
+			r["enableEventReplication"] = body["enableEventReplication"]
+			r["repoKey"] = key
+			replications = append(replications, r)
+		}
+	} else {
+		r := body
+		if method == "POST" {
+			r = replications[0]
+			for k, v := range body {
+				r[k] = v
+			}
+		}
+		r["repoKey"] = key
+		replications = []map[string]interface{}{r}
+	}
+	f.replications[key] = replications
+
+	if method == "PUT" {
+		w.WriteHeader(http.StatusCreated)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func fakeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func fakeError(w http.ResponseWriter, status int, message string) {
+	fakeJSON(w, status, map[string]interface{}{
+		"errors": []map[string]interface{}{{"status": status, "message": message}},
+	})
+}
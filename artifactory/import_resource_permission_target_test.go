@@ -0,0 +1,24 @@
+package artifactory
+
+import (
+	"github.com/hashicorp/terraform/helper/resource"
+	"testing"
+)
+
+func TestAccPermissionTarget_import(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckPermissionTargetDestroy("artifactory_permission_target.foobar"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPermissionTarget_full,
+			},
+			resource.TestStep{
+				ResourceName:      "artifactory_permission_target.foobar",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
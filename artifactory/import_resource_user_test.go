@@ -5,14 +5,27 @@ import (
 	"testing"
 )
 
-func IgnoreTestAccUser_import(t *testing.T) {
+const testAccUser_import = `
+resource "artifactory_group" "lebowski" {
+	name = "acctest-lebowski"
+}
+
+resource "artifactory_user" "foobar" {
+	name        = "walter"
+	email       = "walter.sobchak@domain.com"
+	is_admin    = true
+	is_editable = true
+	groups      = [ "${artifactory_group.lebowski.name}" ]
+}`
+
+func TestAccUser_import(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
 		CheckDestroy: testAccCheckUserDestroy("artifactory_user.foobar"),
 		Providers:    testAccProviders,
 		Steps: []resource.TestStep{
 			resource.TestStep{
-				Config: testAccUser_full,
+				Config: testAccUser_import,
 			},
 			resource.TestStep{
 				ResourceName:      "artifactory_user.foobar",
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/logging"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
 
 	"github.com/webdevwilson/go-artifactory/artifactory"
@@ -22,17 +24,33 @@ func Provider() terraform.ResourceProvider {
 		Schema: map[string]*schema.Schema{
 			"username": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("ARTIFACTORY_USERNAME", nil),
-				Description: "Username for authentication",
+				Description: "Username for basic authentication",
 			},
 
 			"password": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("ARTIFACTORY_PASSWORD", nil),
-				Description: "Password or API Key to use",
+				Description: "Password or API Key to use for basic authentication",
+			},
+
+			"api_key": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("ARTIFACTORY_API_KEY", nil),
+				Description: "API key sent in the X-JFrog-Art-Api header",
+			},
+
+			"access_token": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("ARTIFACTORY_ACCESS_TOKEN", nil),
+				Description: "Access token sent as a bearer token",
 			},
 
 			"url": &schema.Schema{
@@ -41,14 +59,116 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("ARTIFACTORY_URL", nil),
 				Description: "The URL to your Artifactory instance ",
 			},
+
+			"ca_cert_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ARTIFACTORY_CA_CERT_FILE", ""),
+				Description: "Path to a PEM encoded CA certificate to trust in addition to the system CAs",
+			},
+
+			"ca_cert_pem": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ARTIFACTORY_CA_CERT_PEM", ""),
+				Description: "PEM encoded CA certificate to trust in addition to the system CAs",
+			},
+
+			"client_cert": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ARTIFACTORY_CLIENT_CERT", ""),
+				Description: "PEM encoded client certificate, or the path to it, for mutual TLS",
+			},
+
+			"client_key": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("ARTIFACTORY_CLIENT_KEY", ""),
+				Description: "PEM encoded private key of the client certificate, or the path to it",
+			},
+
+			"insecure_skip_verify": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ARTIFACTORY_INSECURE_SKIP_VERIFY", false),
+				Description: "Skip verification of the certificate presented by Artifactory",
+			},
+
+			"proxy_url": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ARTIFACTORY_PROXY_URL", ""),
+				Description: "URL of the proxy to reach Artifactory through, replacing the proxy of the environment",
+			},
+
+			"no_proxy": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ARTIFACTORY_NO_PROXY", ""),
+				Description: "Comma separated hosts, domains and CIDR ranges reached without the proxy",
+			},
+
+			"request_timeout": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("ARTIFACTORY_REQUEST_TIMEOUT", "5m"),
+				ValidateFunc: validateDuration,
+				Description:  "Time after which a request, including its retries, is cancelled. 0 never cancels requests",
+			},
+
+			"max_concurrent_requests": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("ARTIFACTORY_MAX_CONCURRENT_REQUESTS", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of requests sent to Artifactory at the same time, 0 for no limit",
+			},
+
+			"max_retries": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("ARTIFACTORY_MAX_RETRIES", artifactory.DefaultRetryPolicy.MaxRetries),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Number of times a request failing with a transient error is retried",
+			},
+
+			"retry_wait_min": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("ARTIFACTORY_RETRY_WAIT_MIN", artifactory.DefaultRetryPolicy.WaitMin.String()),
+				ValidateFunc: validateDuration,
+				Description:  "Wait before the first retry, doubled for every further retry",
+			},
+
+			"retry_wait_max": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("ARTIFACTORY_RETRY_WAIT_MAX", artifactory.DefaultRetryPolicy.WaitMax.String()),
+				ValidateFunc: validateDuration,
+				Description:  "Maximum wait between retries",
+			},
 		},
 		ConfigureFunc: providerConfigure,
+		DataSourcesMap: map[string]*schema.Resource{
+			"artifactory_repository":   dataSourceRepository(),
+			"artifactory_repositories": dataSourceRepositories(),
+		},
 		ResourcesMap: map[string]*schema.Resource{
 			"artifactory_local_repository":   resourceLocalRepository(),
 			"artifactory_remote_repository":  resourceRemoteRepository(),
 			"artifactory_virtual_repository": resourceVirtualRepository(),
 			"artifactory_user":               resourceUser(),
 			"artifactory_group":              resourceGroup(),
+			"artifactory_group_membership":   resourceGroupMembership(),
+			"artifactory_api_key":            resourceAPIKey(),
+			"artifactory_access_token":       resourceAccessToken(),
+			"artifactory_permission_target":  resourcePermissionTarget(),
+			"artifactory_push_replication":   resourcePushReplication(),
+			"artifactory_pull_replication":   resourcePullReplication(),
+			"artifactory_property_set":       resourcePropertySet(),
+			"artifactory_repo_layout":        resourceRepoLayout(),
 		},
 	}
 }
@@ -56,10 +176,42 @@ func Provider() terraform.ResourceProvider {
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	user := d.Get("username").(string)
 	pass := d.Get("password").(string)
+	apiKey := d.Get("api_key").(string)
+	accessToken := d.Get("access_token").(string)
 	url := d.Get("url").(string)
-	hc := &http.Client{Transport: http.DefaultTransport}
+
+	if err := validateAuthentication(user, pass, apiKey, accessToken); err != nil {
+		return nil, err
+	}
+
+	retry, err := retryPolicy(d)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := newTransport(d)
+	if err != nil {
+		return nil, err
+	}
+
+	hc := &http.Client{Transport: transport}
+	hc.Transport = artifactory.NewLimitTransport(hc.Transport, d.Get("max_concurrent_requests").(int))
 	hc.Transport = logging.NewTransport("Artifactory", hc.Transport)
-	c := artifactory.NewClient(user, pass, url, hc)
+	hc.Transport = artifactory.NewRetryTransport(hc.Transport, retry)
+
+	// validated by validateDuration
+	timeout, _ := time.ParseDuration(d.Get("request_timeout").(string))
+	opts := []artifactory.ClientOption{artifactory.WithRequestTimeout(timeout)}
+
+	var c artifactory.Client
+	switch {
+	case apiKey != "":
+		c = artifactory.NewClientWithAPIKey(apiKey, url, hc, opts...)
+	case accessToken != "":
+		c = artifactory.NewClientWithAccessToken(accessToken, url, hc, opts...)
+	default:
+		c = artifactory.NewClient(user, pass, url, hc, opts...)
+	}
 
 	// fail early. validate the connection to Artifactory
 	if err := c.Ping(); err != nil {
@@ -68,3 +220,53 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 
 	return c, nil
 }
+
+// validateAuthentication ensures exactly one authentication method is configured
+func validateAuthentication(user, pass, apiKey, accessToken string) error {
+	methods := 0
+	if user != "" || pass != "" {
+		if user == "" || pass == "" {
+			return fmt.Errorf("Both username and password must be set for basic authentication")
+		}
+		methods++
+	}
+	if apiKey != "" {
+		methods++
+	}
+	if accessToken != "" {
+		methods++
+	}
+
+	if methods != 1 {
+		return fmt.Errorf("Exactly one of username/password, api_key or access_token must be set, got %d", methods)
+	}
+
+	return nil
+}
+
+func retryPolicy(d *schema.ResourceData) (artifactory.RetryPolicy, error) {
+	// the durations have been validated by validateDuration
+	waitMin, _ := time.ParseDuration(d.Get("retry_wait_min").(string))
+	waitMax, _ := time.ParseDuration(d.Get("retry_wait_max").(string))
+
+	if waitMin > waitMax {
+		return artifactory.RetryPolicy{}, fmt.Errorf("retry_wait_min (%s) must not be greater than retry_wait_max (%s)", waitMin, waitMax)
+	}
+
+	return artifactory.RetryPolicy{
+		MaxRetries: d.Get("max_retries").(int),
+		WaitMin:    waitMin,
+		WaitMax:    waitMax,
+	}, nil
+}
+
+// validateDuration accepts non-negative durations such as "500ms" or "1m30s"
+func validateDuration(v interface{}, k string) (ws []string, errors []error) {
+	d, err := time.ParseDuration(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a duration such as \"30s\": %s", k, err))
+	} else if d < 0 {
+		errors = append(errors, fmt.Errorf("%q must not be negative", k))
+	}
+	return
+}
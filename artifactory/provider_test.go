@@ -3,8 +3,11 @@ package artifactory
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/webdevwilson/go-artifactory/artifactory"
@@ -13,6 +16,10 @@ import (
 var testAccProviders map[string]terraform.ResourceProvider
 var testAccProvider *schema.Provider
 
+// testAccFake is the in-process Artifactory the acceptance tests run against
+// when ARTIFACTORY_URL is not set. It is nil when testing a real instance.
+var testAccFake *fakeArtifactory
+
 func init() {
 	testAccProvider = Provider().(*schema.Provider)
 	testAccProviders = map[string]terraform.ResourceProvider{
@@ -20,6 +27,27 @@ func init() {
 	}
 }
 
+func TestMain(m *testing.M) {
+	if os.Getenv("ARTIFACTORY_URL") == "" {
+		testAccFake = newFakeArtifactory("admin", "password")
+		os.Setenv("ARTIFACTORY_URL", testAccFake.URL)
+		os.Setenv("ARTIFACTORY_USERNAME", testAccFake.username)
+		os.Setenv("ARTIFACTORY_PASSWORD", testAccFake.password)
+		os.Unsetenv("ARTIFACTORY_API_KEY")
+		os.Unsetenv("ARTIFACTORY_ACCESS_TOKEN")
+		// keep the tests of transient errors fast
+		os.Setenv("ARTIFACTORY_RETRY_WAIT_MIN", "10ms")
+		os.Setenv("ARTIFACTORY_RETRY_WAIT_MAX", "100ms")
+	}
+
+	code := m.Run()
+
+	if testAccFake != nil {
+		testAccFake.Close()
+	}
+	os.Exit(code)
+}
+
 func TestProvider(t *testing.T) {
 	if err := Provider().(*schema.Provider).InternalValidate(); err != nil {
 		t.Fatalf("err: %s", err)
@@ -30,18 +58,140 @@ func TestProvider_impl(t *testing.T) {
 	var _ terraform.ResourceProvider = Provider()
 }
 
-func testAccPreCheck(t *testing.T) {
-	if v := os.Getenv("ARTIFACTORY_USERNAME"); v == "" {
-		t.Fatal("ARTIFACTORY_USERNAME must be set for acceptance tests")
+func TestProvider_authentication(t *testing.T) {
+	testAccFakeOnly(t)
+
+	cases := []struct {
+		name   string
+		config map[string]interface{}
+		err    string
+	}{
+		{
+			name:   "basic",
+			config: map[string]interface{}{"username": testAccFake.username, "password": testAccFake.password},
+		},
+		{
+			name:   "api key",
+			config: map[string]interface{}{"api_key": testAccFake.apiKey},
+		},
+		{
+			name:   "access token",
+			config: map[string]interface{}{"access_token": testAccFake.accessToken},
+		},
+		{
+			name:   "none",
+			config: map[string]interface{}{},
+			err:    "Exactly one of",
+		},
+		{
+			name:   "username without password",
+			config: map[string]interface{}{"username": testAccFake.username},
+			err:    "Both username and password",
+		},
+		{
+			name:   "api key and access token",
+			config: map[string]interface{}{"api_key": testAccFake.apiKey, "access_token": testAccFake.accessToken},
+			err:    "Exactly one of",
+		},
+		{
+			name:   "bad api key",
+			config: map[string]interface{}{"api_key": "wrong"},
+			err:    "401",
+		},
+	}
+
+	for _, tc := range cases {
+		raw := map[string]interface{}{
+			"url":          testAccFake.URL,
+			"username":     "",
+			"password":     "",
+			"api_key":      "",
+			"access_token": "",
+		}
+		for k, v := range tc.config {
+			raw[k] = v
+		}
+
+		rc, err := config.NewRawConfig(raw)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+
+		err = Provider().Configure(terraform.NewResourceConfig(rc))
+		if tc.err == "" && err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+		}
+		if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("%s: expected error containing %q, got: %v", tc.name, tc.err, err)
+		}
+	}
+}
+
+func TestProvider_retryAfter(t *testing.T) {
+	testAccFakeOnly(t)
+	defer testAccFake.reset()
+	testAccFake.addFault(&fakeFault{method: "GET", path: "system/ping", status: 429, retryAfter: "1", times: 1})
+
+	rc, err := config.NewRawConfig(map[string]interface{}{
+		"url":            testAccFake.URL,
+		"username":       testAccFake.username,
+		"password":       testAccFake.password,
+		"retry_wait_min": "1ms",
+		"retry_wait_max": "5s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := Provider().Configure(terraform.NewResourceConfig(rc)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected the client to wait for the Retry-After of 1s, retried after %s", elapsed)
+	}
+}
+
+func TestProvider_retryWaitBounds(t *testing.T) {
+	rc, err := config.NewRawConfig(map[string]interface{}{
+		"url":            "http://localhost:1",
+		"username":       "admin",
+		"password":       "password",
+		"retry_wait_min": "10s",
+		"retry_wait_max": "1s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Provider().Configure(terraform.NewResourceConfig(rc))
+	if err == nil || !strings.Contains(err.Error(), "must not be greater than retry_wait_max") {
+		t.Errorf("expected an error about the retry waits, got: %v", err)
 	}
-	if v := os.Getenv("ARTIFACTORY_PASSWORD"); v == "" {
-		t.Fatal("ARTIFACTORY_PASSWORD must be set for acceptance tests")
+}
+
+func testAccPreCheck(t *testing.T) {
+	if os.Getenv("ARTIFACTORY_API_KEY") == "" && os.Getenv("ARTIFACTORY_ACCESS_TOKEN") == "" {
+		if v := os.Getenv("ARTIFACTORY_USERNAME"); v == "" {
+			t.Fatal("ARTIFACTORY_USERNAME must be set for acceptance tests")
+		}
+		if v := os.Getenv("ARTIFACTORY_PASSWORD"); v == "" {
+			t.Fatal("ARTIFACTORY_PASSWORD must be set for acceptance tests")
+		}
 	}
 	if v := os.Getenv("ARTIFACTORY_URL"); v == "" {
 		t.Fatal("ARTIFACTORY_URL must be set for acceptance tests")
 	}
 }
 
+// testAccFakeOnly skips tests that rely on fault injection when running
+// against a real Artifactory instance
+func testAccFakeOnly(t *testing.T) {
+	if testAccFake == nil {
+		t.Skip("fault injection requires the fake Artifactory server")
+	}
+}
+
 func testAccCheckRepositoryDestroy(id string) func(*terraform.State) error {
 	return func(s *terraform.State) error {
 		client := testAccProvider.Meta().(artifactory.Client)
@@ -50,12 +200,17 @@ func testAccCheckRepositoryDestroy(id string) func(*terraform.State) error {
 			return fmt.Errorf("Not found %s", id)
 		}
 
-		err := client.GetRepository(rs.Primary.ID, nil)
+		var repo artifactory.VirtualRepositoryConfiguration
+		err := client.GetRepository(rs.Primary.ID, &repo)
 
 		if err == nil {
 			return fmt.Errorf("Repository %s still exists", rs.Primary.ID)
 		}
 
+		if !artifactory.IsNotFound(err) {
+			return err
+		}
+
 		return nil
 	}
 }
@@ -0,0 +1,163 @@
+package artifactory
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+func resourceAccessToken() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceAccessTokenCreate,
+		Read:   resourceAccessTokenRead,
+		Delete: resourceAccessTokenDelete,
+
+		Schema: map[string]*schema.Schema{
+			"username": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"groups": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"expires_in": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      3600,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"refreshable": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+				Default:  false,
+			},
+			"audience": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"access_token": &schema.Schema{
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"refresh_token": &schema.Schema{
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"expires_at": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func newAccessTokenOptionsFromResource(d *schema.ResourceData) *artifactory.AccessTokenOptions {
+	options := &artifactory.AccessTokenOptions{
+		Username:    d.Get("username").(string),
+		ExpiresIn:   d.Get("expires_in").(int),
+		Refreshable: d.Get("refreshable").(bool),
+		Audience:    d.Get("audience").(string),
+	}
+
+	if v, ok := d.GetOk("groups"); ok {
+		l := v.(*schema.Set).List()
+		groups := make([]string, 0, len(l))
+		for _, g := range l {
+			groups = append(groups, g.(string))
+		}
+		sort.Strings(groups)
+		options.Scope = "member-of-groups:" + strings.Join(groups, ",")
+	}
+
+	return options
+}
+
+func resourceAccessTokenRead(d *schema.ResourceData, m interface{}) error {
+	// Artifactory does not keep short lived tokens, so there is nothing to
+	// read back. An expired token is replaced by a new one.
+	if v := d.Get("expires_at").(string); v != "" {
+		expiresAt, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return err
+		}
+
+		if !time.Now().Before(expiresAt) {
+			log.Printf("[WARN] Access token %s expired at %s, removing from state", d.Id(), v)
+			d.SetId("")
+		}
+	}
+
+	return nil
+}
+
+func resourceAccessTokenCreate(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	issuedAt := time.Now()
+
+	token, err := c.CreateAccessToken(newAccessTokenOptionsFromResource(d))
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(accessTokenID(token.AccessToken))
+	d.Set("access_token", token.AccessToken)
+	d.Set("refresh_token", token.RefreshToken)
+	d.Set("expires_at", "")
+	if token.ExpiresIn > 0 {
+		expiresAt := issuedAt.Add(time.Duration(token.ExpiresIn) * time.Second)
+		d.Set("expires_at", expiresAt.UTC().Format(time.RFC3339))
+	}
+
+	return resourceAccessTokenRead(d, m)
+}
+
+func resourceAccessTokenDelete(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	err := c.RevokeAccessToken(d.Get("access_token").(string))
+
+	if artifactory.IsNotFound(err) {
+		// the token expired, or was never stored as it is short lived
+		return nil
+	}
+
+	return err
+}
+
+// accessTokenID returns the ID of the token, which is the jti claim of the
+// JWT issued by Artifactory
+func accessTokenID(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) == 3 {
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err == nil {
+			var claims struct {
+				ID string `json:"jti"`
+			}
+			if err := json.Unmarshal(payload, &claims); err == nil && claims.ID != "" {
+				return claims.ID
+			}
+		}
+	}
+
+	log.Printf("[WARN] Cannot read the ID of the access token, generating one")
+	return resource.PrefixedUniqueId("token-")
+}
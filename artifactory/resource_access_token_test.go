@@ -0,0 +1,138 @@
+package artifactory
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+const testAccAccessToken_full = `
+resource "artifactory_access_token" "ci" {
+	username    = "ci"
+	groups      = [ "readers", "deployers" ]
+	expires_in  = 7200
+	refreshable = true
+	audience    = "jfrt@*"
+}`
+
+func TestAccAccessToken_full(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_access_token.ci"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckAccessTokens("ci"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccAccessToken_full,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(resourceName, "id", regexp.MustCompile("^fake-token-")),
+					resource.TestMatchResourceAttr(resourceName, "access_token", regexp.MustCompile(`^eyJ`)),
+					resource.TestMatchResourceAttr(resourceName, "refresh_token", regexp.MustCompile(".")),
+					resource.TestMatchResourceAttr(resourceName, "expires_at", regexp.MustCompile(`^\d{4}-\d\d-\d\dT`)),
+					testAccCheckAccessTokens("ci", "member-of-groups:deployers,readers"),
+					testAccCheckAccessTokenAuthenticates(resourceName),
+				),
+			},
+		},
+	})
+}
+
+const testAccAccessToken_expiring = `
+resource "artifactory_access_token" "ci" {
+	username   = "nightly"
+	expires_in = 2
+}`
+
+func TestAccAccessToken_expired(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_access_token.ci"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccAccessToken_expiring,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "refresh_token", ""),
+					testAccCheckAccessTokens("nightly", "member-of-groups:*"),
+				),
+			},
+			resource.TestStep{
+				PreConfig:          func() { time.Sleep(2 * time.Second) },
+				Config:             testAccAccessToken_expiring,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+const testAccAccessToken_invalidScope = `
+resource "artifactory_access_token" "ci" {
+	username = "ci"
+	groups   = [ "readers, deployers" ]
+}`
+
+func TestAccAccessToken_invalidScope(t *testing.T) {
+	testAccFakeOnly(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccAccessToken_invalidScope,
+				ExpectError: regexp.MustCompile("400 Bad Request: Invalid scope"),
+			},
+		},
+	})
+}
+
+func TestAccessTokenID(t *testing.T) {
+	cases := []struct {
+		token  string
+		prefix string
+	}{
+		// {"jti":"0b8d2c59-6a4e-4a3c-8d8f-8f7f2a1f3a2b"}
+		{"eyJhbGciOiJSUzI1NiJ9.eyJqdGkiOiIwYjhkMmM1OS02YTRlLTRhM2MtOGQ4Zi04ZjdmMmExZjNhMmIifQ.sig", "0b8d2c59-6a4e-4a3c-8d8f-8f7f2a1f3a2b"},
+		{"not-a-jwt", "token-"},
+		{"eyJhbGciOiJSUzI1NiJ9.e30.sig", "token-"},
+	}
+
+	for _, c := range cases {
+		if id := accessTokenID(c.token); !strings.HasPrefix(id, c.prefix) {
+			t.Errorf("accessTokenID(%q) = %q, expected it to start with %q", c.token, id, c.prefix)
+		}
+	}
+}
+
+// testAccCheckAccessTokens checks the scopes of the tokens the fake issued for a user
+func testAccCheckAccessTokens(username string, scopes ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if scopes == nil {
+			scopes = []string{}
+		}
+		if got := testAccFake.accessTokens(username); !reflect.DeepEqual(got, scopes) {
+			return fmt.Errorf("expected tokens of %s with scopes %v, got %v", username, scopes, got)
+		}
+		return nil
+	}
+}
+
+func testAccCheckAccessTokenAuthenticates(id string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		token := s.RootModule().Resources[id].Primary.Attributes["access_token"]
+		return artifactory.NewClientWithAccessToken(token, testAccFake.URL, http.DefaultClient).Ping()
+	}
+}
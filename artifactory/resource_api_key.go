@@ -0,0 +1,164 @@
+package artifactory
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+func resourceAPIKey() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceAPIKeyCreate,
+		Read:   resourceAPIKeyRead,
+		Update: resourceAPIKeyUpdate,
+		Delete: resourceAPIKeyDelete,
+
+		Schema: map[string]*schema.Schema{
+			"username": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"password": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"regenerate_existing": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"keepers": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+			},
+			"api_key": &schema.Schema{
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+// apiKeyClient returns the client authenticating as the owner of the API key
+func apiKeyClient(d *schema.ResourceData, m interface{}) (artifactory.Client, error) {
+	c := m.(artifactory.Client)
+
+	username := d.Get("username").(string)
+	if username == "" {
+		return c, nil
+	}
+
+	password := d.Get("password").(string)
+	if password == "" {
+		return nil, fmt.Errorf("password must be set to manage the API key of %s", username)
+	}
+
+	return c.AsUser(username, password), nil
+}
+
+func resourceAPIKeyRead(d *schema.ResourceData, m interface{}) error {
+	c, err := apiKeyClient(d, m)
+	if err != nil {
+		return err
+	}
+
+	key, err := c.GetAPIKey()
+
+	if e, ok := err.(*artifactory.Error); ok && e.StatusCode == http.StatusUnauthorized && d.Get("username").(string) != "" {
+		// the password of the user changed, which leaves the key alone
+		log.Printf("[WARN] Cannot log in as %s to read its API key, keeping the known key", d.Get("username"))
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if key == "" {
+		log.Printf("[WARN] API key %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("api_key", key)
+
+	return nil
+}
+
+func resourceAPIKeyCreate(d *schema.ResourceData, m interface{}) error {
+	// regenerating or revoking the key the provider authenticates with would
+	// break the provider in the middle of the apply
+	if d.Get("username").(string) == "" && m.(artifactory.Client).UsesAPIKey() {
+		return fmt.Errorf("the provider authenticates with the API key of its user, set username to manage the API key of another user")
+	}
+
+	c, err := apiKeyClient(d, m)
+	if err != nil {
+		return err
+	}
+
+	current, err := c.GetAPIKey()
+	if err != nil {
+		return err
+	}
+
+	// a user has a single API key, which may be used outside of Terraform
+	var key string
+	if current != "" {
+		if !d.Get("regenerate_existing").(bool) {
+			return fmt.Errorf("%s already has an API key, set regenerate_existing to replace it", apiKeyOwner(d))
+		}
+		key, err = c.RegenerateAPIKey()
+	} else {
+		key, err = c.CreateAPIKey()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(resource.PrefixedUniqueId("apikey-"))
+	d.Set("api_key", key)
+	return resourceAPIKeyRead(d, m)
+}
+
+func resourceAPIKeyUpdate(d *schema.ResourceData, m interface{}) error {
+	// only the password can change, which is used to read the key
+	return resourceAPIKeyRead(d, m)
+}
+
+func resourceAPIKeyDelete(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	username := d.Get("username").(string)
+
+	if username == "" && c.UsesAPIKey() {
+		log.Printf("[WARN] The provider authenticates with API key %s, leaving it alone", d.Id())
+		return nil
+	}
+
+	// the key of another user is revoked with admin privileges, so it works
+	// even when the user can no longer log in
+	err := c.RevokeAPIKey(username)
+
+	if artifactory.IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
+
+// apiKeyOwner describes the user owning the API key in errors
+func apiKeyOwner(d *schema.ResourceData) string {
+	if username := d.Get("username").(string); username != "" {
+		return fmt.Sprintf("user %s", username)
+	}
+	return "the user of the provider"
+}
@@ -0,0 +1,220 @@
+package artifactory
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+func testAccAPIKey_keepers(rotation string) string {
+	return fmt.Sprintf(`
+resource "artifactory_api_key" "foobar" {
+	keepers {
+		rotation = "%s"
+	}
+}`, rotation)
+}
+
+func TestAccAPIKey_keepers(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_api_key.foobar"
+	var first string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckAPIKeyRevoked("admin"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccAPIKey_keepers("1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckAPIKey(resourceName, "admin"),
+					func(s *terraform.State) error {
+						first = s.RootModule().Resources[resourceName].Primary.Attributes["api_key"]
+						return nil
+					},
+				),
+			},
+			resource.TestStep{
+				Config: testAccAPIKey_keepers("2"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckAPIKey(resourceName, "admin"),
+					func(s *terraform.State) error {
+						if s.RootModule().Resources[resourceName].Primary.Attributes["api_key"] == first {
+							return fmt.Errorf("expected the API key to be regenerated")
+						}
+						return nil
+					},
+				),
+			},
+			resource.TestStep{
+				PreConfig:          func() { testAccFake.removeOutOfBand("security/apiKey/admin") },
+				Config:             testAccAPIKey_keepers("2"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func testAccAPIKey_serviceAccount(password string) string {
+	return fmt.Sprintf(`
+resource "artifactory_user" "ci" {
+	name     = "ci"
+	email    = "ci@domain.com"
+	password = "%s"
+}
+
+resource "artifactory_api_key" "ci" {
+	username = "${artifactory_user.ci.name}"
+	password = "${artifactory_user.ci.password}"
+}`, password)
+}
+
+func TestAccAPIKey_serviceAccount(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_api_key.ci"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckAPIKeyRevoked("ci"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccAPIKey_serviceAccount("Sh0mer-Shabbos!"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckAPIKey(resourceName, "ci"),
+					testAccCheckAPIKeyAuthenticates(resourceName),
+				),
+			},
+			resource.TestStep{
+				// a new password is used to read the key, which is kept
+				Config: testAccAPIKey_serviceAccount("Over-The-L1ne!"),
+				Check:  testAccCheckAPIKey(resourceName, "ci"),
+			},
+			resource.TestStep{
+				// the key is still known when the user cannot log in
+				PreConfig: func() {
+					testAccFake.updateOutOfBand("security/users/ci", map[string]interface{}{"password": "Nihilist-99!"})
+				},
+				Config: testAccAPIKey_serviceAccount("Over-The-L1ne!"),
+				Check:  testAccCheckAPIKey(resourceName, "ci"),
+			},
+		},
+	})
+}
+
+func testAccAPIKey_existing(regenerate bool) string {
+	return fmt.Sprintf(`
+resource "artifactory_api_key" "foobar" {
+	regenerate_existing = %t
+}`, regenerate)
+}
+
+func TestAccAPIKey_existing(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_api_key.foobar"
+	var existing string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckAPIKeyRevoked("admin"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				PreConfig: func() {
+					c := artifactory.NewClient(testAccFake.username, testAccFake.password, testAccFake.URL, http.DefaultClient)
+					key, err := c.CreateAPIKey()
+					if err != nil {
+						t.Fatal(err)
+					}
+					existing = key
+				},
+				Config:      testAccAPIKey_existing(false),
+				ExpectError: regexp.MustCompile("already has an API key, set regenerate_existing"),
+			},
+			resource.TestStep{
+				Config: testAccAPIKey_existing(true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckAPIKey(resourceName, "admin"),
+					func(s *terraform.State) error {
+						if s.RootModule().Resources[resourceName].Primary.Attributes["api_key"] == existing {
+							return fmt.Errorf("expected the existing API key to be regenerated")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+// TestAPIKey_providerAPIKey checks the key the provider authenticates with is
+// neither regenerated nor revoked
+func TestAPIKey_providerAPIKey(t *testing.T) {
+	testAccFakeOnly(t)
+	defer testAccFake.removeOutOfBand("security/apiKey/admin")
+
+	key, err := artifactory.NewClient(testAccFake.username, testAccFake.password, testAccFake.URL, http.DefaultClient).CreateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := artifactory.NewClientWithAPIKey(key, testAccFake.URL, http.DefaultClient)
+
+	d := resourceAPIKey().TestResourceData()
+	d.Set("regenerate_existing", true)
+	err = resourceAPIKeyCreate(d, c)
+	if err == nil || !strings.Contains(err.Error(), "set username") {
+		t.Errorf("expected an error about the API key of the provider, got: %v", err)
+	}
+	if testAccFake.apiKeyOf("admin") != key {
+		t.Errorf("expected the API key of the provider to be kept on create")
+	}
+
+	// the key may have been managed before the provider authenticated with it
+	d.SetId("apikey-provider")
+	if err := resourceAPIKeyDelete(d, c); err != nil {
+		t.Fatal(err)
+	}
+	if testAccFake.apiKeyOf("admin") != key {
+		t.Errorf("expected the API key of the provider to be kept on destroy")
+	}
+}
+
+// testAccCheckAPIKey checks the API key in the state is the one of the user
+func testAccCheckAPIKey(id, username string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found %s", id)
+		}
+
+		key := testAccFake.apiKeyOf(username)
+		if key == "" || rs.Primary.Attributes["api_key"] != key {
+			return fmt.Errorf("expected api_key to be the API key of %s", username)
+		}
+		return nil
+	}
+}
+
+func testAccCheckAPIKeyAuthenticates(id string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		key := s.RootModule().Resources[id].Primary.Attributes["api_key"]
+		return artifactory.NewClientWithAPIKey(key, testAccFake.URL, http.DefaultClient).Ping()
+	}
+}
+
+func testAccCheckAPIKeyRevoked(username string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if testAccFake.apiKeyOf(username) != "" {
+			return fmt.Errorf("API key of %s still exists", username)
+		}
+		return nil
+	}
+}
@@ -1,6 +1,8 @@
 package artifactory
 
 import (
+	"log"
+
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/webdevwilson/go-artifactory/artifactory"
 )
@@ -20,11 +22,20 @@ func resourceGroup() *schema.Resource {
 				Required: true,
 				ForceNew: true,
 			},
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
 			"auto_join": &schema.Schema{
 				Type:     schema.TypeBool,
 				Optional: true,
 				Default:  false,
 			},
+			"admin_privileges": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"realm": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
@@ -33,6 +44,11 @@ func resourceGroup() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"users_names": &schema.Schema{
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
 		},
 	}
 }
@@ -45,10 +61,18 @@ func newGroupFromResource(d *schema.ResourceData) *artifactory.Group {
 		group.Name = v.(string)
 	}
 
+	if v, ok := d.GetOk("description"); ok {
+		group.Description = v.(string)
+	}
+
 	if v, ok := d.GetOk("auto_join"); ok {
 		group.AutoJoin = v.(bool)
 	}
 
+	if v, ok := d.GetOk("admin_privileges"); ok {
+		group.AdminPrivileges = v.(bool)
+	}
+
 	if v, ok := d.GetOk("realm"); ok {
 		group.Realm = v.(string)
 	}
@@ -63,16 +87,25 @@ func newGroupFromResource(d *schema.ResourceData) *artifactory.Group {
 func resourceGroupRead(d *schema.ResourceData, m interface{}) error {
 	c := m.(artifactory.Client)
 
-	group, err := c.GetGroup(d.Get("name").(string))
+	group, err := c.GetGroupWithUsers(d.Id())
+
+	if artifactory.IsNotFound(err) {
+		log.Printf("[WARN] Group %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
 
 	if err != nil {
 		return err
 	}
 
 	d.Set("name", group.Name)
+	d.Set("description", group.Description)
 	d.Set("auto_join", group.AutoJoin)
+	d.Set("admin_privileges", group.AdminPrivileges)
 	d.Set("realm", group.Realm)
 	d.Set("realm_attributes", group.RealmAttributes)
+	d.Set("users_names", group.UsersNames)
 
 	return nil
 }
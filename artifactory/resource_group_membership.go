@@ -0,0 +1,226 @@
+package artifactory
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+const (
+	groupMembershipAdditive      = "additive"
+	groupMembershipAuthoritative = "authoritative"
+)
+
+// userMutexKV serializes updates of the groups of a user, which replace all of them
+var userMutexKV = newMutexKV()
+
+func resourceGroupMembership() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceGroupMembershipCreate,
+		Read:   resourceGroupMembershipRead,
+		Update: resourceGroupMembershipUpdate,
+		Delete: resourceGroupMembershipDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceGroupMembershipImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"group": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"users": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+				Required: true,
+			},
+			"mode": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      groupMembershipAdditive,
+				ValidateFunc: validation.StringInSlice([]string{groupMembershipAdditive, groupMembershipAuthoritative}, false),
+			},
+		},
+	}
+}
+
+func resourceGroupMembershipRead(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+
+	group, err := c.GetGroupWithUsers(d.Id())
+
+	if artifactory.IsNotFound(err) {
+		log.Printf("[WARN] Group %s not found, removing membership from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	users := group.UsersNames
+	if d.Get("mode").(string) == groupMembershipAdditive {
+		// members that are not configured are managed elsewhere
+		configured := d.Get("users").(*schema.Set)
+		users = make([]string, 0, len(group.UsersNames))
+		for _, u := range group.UsersNames {
+			if configured.Contains(u) {
+				users = append(users, u)
+			}
+		}
+	}
+
+	d.Set("group", group.Name)
+	d.Set("users", users)
+
+	return nil
+}
+
+func resourceGroupMembershipCreate(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	group := d.Get("group").(string)
+
+	if err := addGroupMembers(c, group, d.Get("users").(*schema.Set).List()); err != nil {
+		return err
+	}
+
+	d.SetId(group)
+
+	if err := removeUnconfiguredGroupMembers(c, d); err != nil {
+		return err
+	}
+
+	return resourceGroupMembershipRead(d, m)
+}
+
+func resourceGroupMembershipUpdate(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+
+	if d.HasChange("users") {
+		o, n := d.GetChange("users")
+		removed := o.(*schema.Set).Difference(n.(*schema.Set)).List()
+		added := n.(*schema.Set).Difference(o.(*schema.Set)).List()
+
+		if err := removeGroupMembers(c, d.Id(), removed); err != nil {
+			return err
+		}
+		if err := addGroupMembers(c, d.Id(), added); err != nil {
+			return err
+		}
+	}
+
+	if err := removeUnconfiguredGroupMembers(c, d); err != nil {
+		return err
+	}
+
+	return resourceGroupMembershipRead(d, m)
+}
+
+func resourceGroupMembershipDelete(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	return removeGroupMembers(c, d.Id(), d.Get("users").(*schema.Set).List())
+}
+
+func resourceGroupMembershipImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	// an imported membership owns all members of the group
+	d.Set("mode", groupMembershipAuthoritative)
+	return []*schema.ResourceData{d}, nil
+}
+
+// removeUnconfiguredGroupMembers removes the members of the group that are
+// not configured, if the membership is authoritative
+func removeUnconfiguredGroupMembers(c artifactory.Client, d *schema.ResourceData) error {
+	if d.Get("mode").(string) != groupMembershipAuthoritative {
+		return nil
+	}
+
+	group, err := c.GetGroupWithUsers(d.Id())
+	if err != nil {
+		return err
+	}
+
+	configured := d.Get("users").(*schema.Set)
+	unconfigured := make([]interface{}, 0)
+	for _, u := range group.UsersNames {
+		if !configured.Contains(u) {
+			unconfigured = append(unconfigured, u)
+		}
+	}
+
+	return removeGroupMembers(c, d.Id(), unconfigured)
+}
+
+func addGroupMembers(c artifactory.Client, group string, users []interface{}) error {
+	for _, u := range users {
+		err := updateUserGroups(c, u.(string), func(groups []string) []string {
+			for _, g := range groups {
+				if g == group {
+					return groups
+				}
+			}
+			return append(groups, group)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeGroupMembers(c artifactory.Client, group string, users []interface{}) error {
+	for _, u := range users {
+		err := updateUserGroups(c, u.(string), func(groups []string) []string {
+			result := make([]string, 0, len(groups))
+			for _, g := range groups {
+				if g != group {
+					result = append(result, g)
+				}
+			}
+			return result
+		})
+
+		if artifactory.IsNotFound(err) {
+			// a deleted user is no longer a member
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateUserGroups replaces the groups of a user by the result of update,
+// holding a lock on the user so concurrent changes by other resources are not lost
+func updateUserGroups(c artifactory.Client, name string, update func([]string) []string) error {
+	userMutexKV.Lock(name)
+	defer userMutexKV.Unlock(name)
+
+	user, err := c.GetUser(name)
+	if err != nil {
+		return err
+	}
+
+	groups := update(user.Groups)
+	if len(groups) == len(user.Groups) {
+		same := true
+		for i := range groups {
+			same = same && groups[i] == user.Groups[i]
+		}
+		if same {
+			return nil
+		}
+	}
+
+	// send only what is needed to change the groups
+	return c.UpdateUser(&artifactory.User{
+		Name:             user.Name,
+		Admin:            user.Admin,
+		ProfileUpdatable: user.ProfileUpdatable,
+		Groups:           groups,
+	})
+}
@@ -0,0 +1,273 @@
+package artifactory
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+const testAccGroupMembership_users = `
+resource "artifactory_group" "lebowski" {
+	name = "acctest-lebowski"
+}
+
+resource "artifactory_user" "dude" {
+	name  = "the.dude"
+	email = "the.dude@domain.com"
+
+	lifecycle {
+		ignore_changes = ["groups"]
+	}
+}
+
+resource "artifactory_user" "walter" {
+	name  = "walter"
+	email = "walter.sobchak@domain.com"
+
+	lifecycle {
+		ignore_changes = ["groups"]
+	}
+}
+
+resource "artifactory_user" "donny" {
+	name  = "donny"
+	email = "donny@domain.com"
+
+	lifecycle {
+		ignore_changes = ["groups"]
+	}
+}`
+
+const testAccGroupMembership_additive = testAccGroupMembership_users + `
+resource "artifactory_group_membership" "lebowski" {
+	group = "${artifactory_group.lebowski.name}"
+	users = [ "${artifactory_user.dude.name}", "${artifactory_user.walter.name}" ]
+}`
+
+const testAccGroupMembership_authoritative = testAccGroupMembership_users + `
+resource "artifactory_group_membership" "lebowski" {
+	group = "${artifactory_group.lebowski.name}"
+	users = [ "${artifactory_user.dude.name}", "${artifactory_user.walter.name}" ]
+	mode  = "authoritative"
+}`
+
+func TestAccGroupMembership_additive(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_group_membership.lebowski"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckGroupDestroy("artifactory_group.lebowski"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccGroupMembership_additive,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "group", "acctest-lebowski"),
+					resource.TestCheckResourceAttr(resourceName, "users.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "mode", "additive"),
+					testAccCheckUserGroups("the.dude", "acctest-lebowski", "readers"),
+					testAccCheckUserGroups("walter", "acctest-lebowski", "readers"),
+					testAccCheckUserGroups("donny", "readers"),
+				),
+			},
+			resource.TestStep{
+				// members added elsewhere are ignored
+				PreConfig: func() { testAccFake.setUserGroups("donny", "acctest-lebowski", "readers") },
+				Config:    testAccGroupMembership_additive,
+				PlanOnly:  true,
+			},
+			resource.TestStep{
+				// removing a configured member is detected
+				PreConfig:          func() { testAccFake.setUserGroups("walter", "readers") },
+				Config:             testAccGroupMembership_additive,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			resource.TestStep{
+				Config: testAccGroupMembership_additive,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "users.#", "2"),
+					testAccCheckUserGroups("walter", "acctest-lebowski", "readers"),
+				),
+			},
+			resource.TestStep{
+				// deleting the membership only removes the configured members
+				Config: testAccGroupMembership_users,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserGroups("the.dude", "readers"),
+					testAccCheckUserGroups("walter", "readers"),
+					testAccCheckUserGroups("donny", "acctest-lebowski", "readers"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccGroupMembership_authoritative(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_group_membership.lebowski"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckGroupDestroy("artifactory_group.lebowski"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccGroupMembership_authoritative,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "users.#", "2"),
+					testAccCheckUserGroups("the.dude", "acctest-lebowski", "readers"),
+					testAccCheckUserGroups("walter", "acctest-lebowski", "readers"),
+				),
+			},
+			resource.TestStep{
+				// members added elsewhere are detected
+				PreConfig:          func() { testAccFake.setUserGroups("donny", "acctest-lebowski", "readers") },
+				Config:             testAccGroupMembership_authoritative,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			resource.TestStep{
+				// and removed
+				Config: testAccGroupMembership_authoritative,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "users.#", "2"),
+					testAccCheckUserGroups("donny", "readers"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+const testAccGroupMembership_user = `
+resource "artifactory_group" "lebowski" {
+	name = "acctest-lebowski"
+}
+
+resource "artifactory_user" "dude" {
+	name  = "the.dude"
+	email = "the.dude@domain.com"
+
+	lifecycle {
+		ignore_changes = ["groups"]
+	}
+}`
+
+// the user is updated after the membership, with the groups it had before
+const testAccGroupMembership_userUpdated = `
+resource "artifactory_group" "lebowski" {
+	name = "acctest-lebowski"
+}
+
+resource "artifactory_group_membership" "lebowski" {
+	group = "${artifactory_group.lebowski.name}"
+	users = [ "the.dude" ]
+}
+
+resource "artifactory_user" "dude" {
+	name       = "the.dude"
+	email      = "el.duderino@domain.com"
+	depends_on = ["artifactory_group_membership.lebowski"]
+
+	lifecycle {
+		ignore_changes = ["groups"]
+	}
+}`
+
+func TestAccGroupMembership_userUpdated(t *testing.T) {
+	testAccFakeOnly(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckGroupDestroy("artifactory_group.lebowski"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccGroupMembership_user,
+				Check:  testAccCheckUserGroups("the.dude", "readers"),
+			},
+			resource.TestStep{
+				// updating the user keeps the groups it was added to by the membership
+				Config: testAccGroupMembership_userUpdated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("artifactory_user.dude", "email", "el.duderino@domain.com"),
+					testAccCheckUserGroups("the.dude", "acctest-lebowski", "readers"),
+				),
+			},
+		},
+	})
+}
+
+func testAccGroupMembership_concurrent(users int) string {
+	return fmt.Sprintf(`
+resource "artifactory_group" "lebowski" {
+	name = "acctest-lebowski"
+}
+
+resource "artifactory_group" "bowling" {
+	name = "acctest-bowling"
+}
+
+resource "artifactory_group" "achievers" {
+	name = "acctest-achievers"
+}
+
+resource "artifactory_user" "league" {
+	count = %d
+	name  = "bowler-${count.index}"
+	email = "bowler-${count.index}@domain.com"
+
+	lifecycle {
+		ignore_changes = ["groups"]
+	}
+}
+
+resource "artifactory_group_membership" "lebowski" {
+	group = "${artifactory_group.lebowski.name}"
+	users = [ "${artifactory_user.league.*.name}" ]
+}
+
+resource "artifactory_group_membership" "bowling" {
+	group = "${artifactory_group.bowling.name}"
+	users = [ "${artifactory_user.league.*.name}" ]
+}
+
+resource "artifactory_group_membership" "achievers" {
+	group = "${artifactory_group.achievers.name}"
+	users = [ "${artifactory_user.league.*.name}" ]
+}`, users)
+}
+
+func TestAccGroupMembership_concurrent(t *testing.T) {
+	testAccFakeOnly(t)
+	defer testAccFake.reset()
+	// give concurrent updates of the same user a chance to overlap
+	testAccFake.setLatency(10 * time.Millisecond)
+
+	users := 4
+	checks := make([]resource.TestCheckFunc, 0, users)
+	for i := 0; i < users; i++ {
+		checks = append(checks, testAccCheckUserGroups(fmt.Sprintf("bowler-%d", i),
+			"acctest-achievers", "acctest-bowling", "acctest-lebowski", "readers"))
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckGroupDestroy("artifactory_group.lebowski"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccGroupMembership_concurrent(users),
+				Check:  resource.ComposeTestCheckFunc(checks...),
+			},
+		},
+	})
+}
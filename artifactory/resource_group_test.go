@@ -2,7 +2,9 @@ package artifactory
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
@@ -30,10 +32,33 @@ func TestAccGroup_basic(t *testing.T) {
 	})
 }
 
+const testAccGroup_specialName = `
+resource "artifactory_group" "foobar" {
+	name = "acctest league #1?"
+}`
+
+func TestAccGroup_specialName(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckGroupDestroy("artifactory_group.foobar"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccGroup_specialName,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("artifactory_group.foobar", "name", "acctest league #1?"),
+				),
+			},
+		},
+	})
+}
+
 const testAccGroup_full = `
 resource "artifactory_group" "foobar" {
 	name             = "acctest-full"
+	description      = "A group for the whole league"
     auto_join        = true
+	admin_privileges = true
 }`
 
 func TestAccGroup_full(t *testing.T) {
@@ -46,7 +71,161 @@ func TestAccGroup_full(t *testing.T) {
 				Config: testAccGroup_full,
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr("artifactory_group.foobar", "name", "acctest-full"),
+					resource.TestCheckResourceAttr("artifactory_group.foobar", "description", "A group for the whole league"),
 					resource.TestCheckResourceAttr("artifactory_group.foobar", "auto_join", "true"),
+					resource.TestCheckResourceAttr("artifactory_group.foobar", "admin_privileges", "true"),
+				),
+			},
+		},
+	})
+}
+
+const testAccGroup_realm = `
+resource "artifactory_group" "foobar" {
+	name             = "acctest-realm"
+	description      = "Synchronized from LDAP"
+	realm            = "ldap"
+	realm_attributes = "ldapGroupName=lebowski;groupsStrategy=STATIC;groupDn=cn=lebowski,ou=groups"
+}
+
+resource "artifactory_user" "dude" {
+	name   = "the.dude"
+	email  = "the.dude@domain.com"
+	groups = [ "${artifactory_group.foobar.name}" ]
+}`
+
+func TestAccGroup_realm(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_group.foobar"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckGroupDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccGroup_realm,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "realm", "ldap"),
+					resource.TestCheckResourceAttr(resourceName, "realm_attributes", "ldapGroupName=lebowski;groupsStrategy=STATIC;groupDn=cn=lebowski,ou=groups"),
+					resource.TestCheckResourceAttr(resourceName, "admin_privileges", "false"),
+				),
+			},
+			resource.TestStep{
+				// the members are only known once the user joined the group
+				Config: testAccGroup_realm,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "users_names.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "users_names.0", "the.dude"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+const testAccGroup_drift = `
+resource "artifactory_group" "foobar" {
+	name             = "acctest-drift"
+	description      = "A group for the whole league"
+	auto_join        = true
+	admin_privileges = true
+	realm            = "ldap"
+	realm_attributes = "ldapGroupName=lebowski"
+}`
+
+func TestAccGroup_drift(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_group.foobar"
+
+	steps := []resource.TestStep{
+		resource.TestStep{
+			Config: testAccGroup_drift,
+		},
+	}
+	for attr, value := range map[string]interface{}{
+		"description":     "Changed in the UI",
+		"autoJoin":        false,
+		"adminPrivileges": false,
+		"realm":           "crowd",
+		"realmAttributes": "groupName=league",
+	} {
+		attrs := map[string]interface{}{attr: value}
+		steps = append(steps,
+			resource.TestStep{
+				PreConfig:          func() { testAccFake.updateOutOfBand("security/groups/acctest-drift", attrs) },
+				Config:             testAccGroup_drift,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			resource.TestStep{
+				Config: testAccGroup_drift,
+			},
+		)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckGroupDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps:        steps,
+	})
+}
+
+func TestAccGroup_serverError(t *testing.T) {
+	testAccFakeOnly(t)
+	defer testAccFake.reset()
+	testAccFake.fail("PUT", "security/groups/acctest-basic", 500, 1)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccGroup_basic,
+				ExpectError: regexp.MustCompile("500 Internal Server Error"),
+			},
+		},
+	})
+}
+
+func TestAccGroup_removedOutOfBand(t *testing.T) {
+	testAccFakeOnly(t)
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccGroup_basic,
+			},
+			resource.TestStep{
+				PreConfig:          func() { testAccFake.removeOutOfBand("security/groups/acctest-basic") },
+				Config:             testAccGroup_basic,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccGroup_slowServer(t *testing.T) {
+	testAccFakeOnly(t)
+	defer testAccFake.reset()
+	testAccFake.setLatency(50 * time.Millisecond)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckGroupDestroy("artifactory_group.foobar"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccGroup_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("artifactory_group.foobar", "name", "acctest-basic"),
 				),
 			},
 		},
@@ -70,3 +249,41 @@ func testAccCheckGroupDestroy(id string) func(*terraform.State) error {
 		return nil
 	}
 }
+
+func TestAccGroup_transientErrors(t *testing.T) {
+	testAccFakeOnly(t)
+	defer testAccFake.reset()
+	testAccFake.fail("PUT", "security/groups/acctest-basic", 503, 2)
+	testAccFake.fail("GET", "security/groups/acctest-basic", 502, 1)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckGroupDestroy("artifactory_group.foobar"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccGroup_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("artifactory_group.foobar", "name", "acctest-basic"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccGroup_retriesExhausted(t *testing.T) {
+	testAccFakeOnly(t)
+	defer testAccFake.reset()
+	testAccFake.fail("PUT", "security/groups/acctest-basic", 503, 0)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccGroup_basic,
+				ExpectError: regexp.MustCompile("503 Service Unavailable"),
+			},
+		},
+	})
+}
@@ -4,7 +4,6 @@ import (
 	"log"
 	"time"
 
-	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/webdevwilson/go-artifactory/artifactory"
@@ -20,6 +19,11 @@ func resourceLocalRepository() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+		},
+		CustomizeDiff: localRepositoryPackageTypeAttributes.customizeDiff(resourceLocalRepository),
 		Schema: map[string]*schema.Schema{
 			"key": &schema.Schema{
 				Type:     schema.TypeString,
@@ -52,8 +56,8 @@ func resourceLocalRepository() *schema.Resource {
 			},
 			"repo_layout_ref": &schema.Schema{
 				Type:     schema.TypeString,
-				Default:  "maven-2-default",
 				Optional: true,
+				Computed: true,
 			},
 			"handle_releases": &schema.Schema{
 				Type:     schema.TypeBool,
@@ -126,10 +130,55 @@ func resourceLocalRepository() *schema.Resource {
 				Optional: true,
 				Default:  false,
 			},
+			"alpine": repositoryFormatSchema(map[string]*schema.Schema{
+				"primary_keypair_ref": &schema.Schema{
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			}),
+			"cargo": repositoryFormatSchema(map[string]*schema.Schema{
+				"anonymous_access": &schema.Schema{
+					Type:     schema.TypeBool,
+					Optional: true,
+				},
+			}),
+			"helm": repositoryFormatSchema(map[string]*schema.Schema{
+				"chart_version_suffix": &schema.Schema{
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			}),
+			"terraform": repositoryFormatSchema(map[string]*schema.Schema{
+				"registry_type": &schema.Schema{
+					Type:         schema.TypeString,
+					Required:     true,
+					ForceNew:     true,
+					ValidateFunc: validation.StringInSlice([]string{"module", "provider"}, false),
+				},
+			}),
 		},
 	}
 }
 
+// localRepositoryPackageTypeAttributes are the attributes of local repositories
+// that only apply to some package types
+var localRepositoryPackageTypeAttributes = packageTypeAttributes{
+	"max_unique_snapshots":            mavenPackageTypes,
+	"checksum_policy_type":            mavenPackageTypes,
+	"snapshot_version_behavior":       mavenPackageTypes,
+	"suppress_pom_consistency_checks": mavenPackageTypes,
+	"max_unique_tags":                 {"docker"},
+	"docker_api_version":              {"docker"},
+	"debian_trivial_layout":           {"debian"},
+	"calculate_yum_metadata":          {"rpm"},
+	"yum_root_depth":                  {"rpm"},
+	"enable_file_lists_indexing":      {"rpm"},
+	"alpine":                          {"alpine"},
+	"cargo":                           {"cargo"},
+	"helm":                            {"helm"},
+	"terraform":                       {"terraform"},
+}
+
 func newLocalRepositoryFromResource(d *schema.ResourceData) *artifactory.LocalRepositoryConfiguration {
 
 	props := make([]string, 0, len(d.Get("property_sets").(*schema.Set).List()))
@@ -138,7 +187,7 @@ func newLocalRepositoryFromResource(d *schema.ResourceData) *artifactory.LocalRe
 		props = append(props, p.(string))
 	}
 
-	return &artifactory.LocalRepositoryConfiguration{
+	repo := &artifactory.LocalRepositoryConfiguration{
 		Key:                          d.Get("key").(string),
 		RClass:                       "local",
 		PackageType:                  d.Get("package_type").(string),
@@ -147,41 +196,57 @@ func newLocalRepositoryFromResource(d *schema.ResourceData) *artifactory.LocalRe
 		IncludesPattern:              d.Get("includes_pattern").(string),
 		ExcludesPattern:              d.Get("excludes_pattern").(string),
 		RepoLayoutRef:                d.Get("repo_layout_ref").(string),
-		HandleReleases:               d.Get("handle_releases").(bool),
-		HandleSnapshots:              d.Get("handle_snapshots").(bool),
-		MaxUniqueSnapshots:           d.Get("max_unique_snapshots").(int),
-		DebianTrivialLayout:          d.Get("debian_trivial_layout").(bool),
+		HandleReleases:               getBoolRef(d, "handle_releases"),
+		HandleSnapshots:              getBoolRef(d, "handle_snapshots"),
+		MaxUniqueSnapshots:           getIntRef(d, "max_unique_snapshots"),
+		DebianTrivialLayout:          getBoolRef(d, "debian_trivial_layout"),
 		ChecksumPolicyType:           d.Get("checksum_policy_type").(string),
-		MaxUniqueTags:                d.Get("max_unique_tags").(int),
+		MaxUniqueTags:                getIntRef(d, "max_unique_tags"),
 		SnapshotVersionBehavior:      d.Get("snapshot_version_behavior").(string),
-		SuppressPomConsistencyChecks: d.Get("suppress_pom_consistency_checks").(bool),
-		BlackedOut:                   d.Get("blacked_out").(bool),
-		ArchiveBrowsingEnabled:       d.Get("archive_browsing_enabled").(bool),
-		CalculateYumMetadata:         d.Get("calculate_yum_metadata").(bool),
-		YumRootDepth:                 d.Get("yum_root_depth").(int),
+		SuppressPomConsistencyChecks: getBoolRef(d, "suppress_pom_consistency_checks"),
+		BlackedOut:                   getBoolRef(d, "blacked_out"),
+		ArchiveBrowsingEnabled:       getBoolRef(d, "archive_browsing_enabled"),
+		CalculateYumMetadata:         getBoolRef(d, "calculate_yum_metadata"),
+		YumRootDepth:                 getIntRef(d, "yum_root_depth"),
 		DockerAPIVersion:             d.Get("docker_api_version").(string),
-		EnableFileListsIndexing:      d.Get("enable_file_lists_indexing").(bool),
+		EnableFileListsIndexing:      getBoolRef(d, "enable_file_lists_indexing"),
 		PropertySets:                 props,
 	}
-}
 
-func repoCreateWait() resource.StateChangeConf {
-	return resource.StateChangeConf{
-		Pending:                   []string{"updating"},
-		Target:                    []string{"updated"},
-		Timeout:                   5 * time.Minute,
-		MinTimeout:                2 * time.Second,
-		ContinuousTargetOccurence: 15,
+	if alpine := getFormatBlock(d, "alpine"); alpine != nil {
+		repo.PrimaryKeyPairRef = artifactory.String(alpine["primary_keypair_ref"].(string))
 	}
+
+	if cargo := getFormatBlock(d, "cargo"); cargo != nil {
+		repo.CargoAnonymousAccess = artifactory.Bool(cargo["anonymous_access"].(bool))
+	}
+
+	if helm := getFormatBlock(d, "helm"); helm != nil {
+		repo.ChartVersionSuffix = artifactory.String(helm["chart_version_suffix"].(string))
+	}
+
+	if terraform := getFormatBlock(d, "terraform"); terraform != nil {
+		repo.TerraformType = artifactory.String(terraform["registry_type"].(string))
+	}
+
+	return repo
 }
 
 func resourceLocalRepositoryCreate(d *schema.ResourceData, m interface{}) error {
 	c := m.(artifactory.Client)
 	repo := newLocalRepositoryFromResource(d)
 
-	c.CreateRepository(repo.Key, repo)
+	if err := c.CreateRepository(repo.Key, repo); err != nil {
+		return err
+	}
+
 	d.SetId(repo.Key)
-	return resourceLocalRepositoryUpdate(d, m)
+
+	if err := waitForRepository(c, repo.Key, repo, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return err
+	}
+
+	return resourceLocalRepositoryRead(d, m)
 }
 
 func resourceLocalRepositoryRead(d *schema.ResourceData, m interface{}) error {
@@ -192,6 +257,12 @@ func resourceLocalRepositoryRead(d *schema.ResourceData, m interface{}) error {
 
 	err := c.GetRepository(key, &repo)
 
+	if artifactory.IsNotFound(err) {
+		log.Printf("[WARN] Local repository %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
 	if err != nil {
 		return err
 	}
@@ -204,20 +275,20 @@ func resourceLocalRepositoryRead(d *schema.ResourceData, m interface{}) error {
 	d.Set("includes_pattern", repo.IncludesPattern)
 	d.Set("excludes_pattern", repo.ExcludesPattern)
 	d.Set("repo_layout_ref", repo.RepoLayoutRef)
-	d.Set("handle_releases", repo.HandleReleases)
-	d.Set("handle_snapshots", repo.HandleSnapshots)
-	d.Set("max_unique_snapshots", repo.MaxUniqueSnapshots)
-	d.Set("debian_trivial_layout", repo.DebianTrivialLayout)
+	d.Set("handle_releases", artifactory.BoolValue(repo.HandleReleases))
+	d.Set("handle_snapshots", artifactory.BoolValue(repo.HandleSnapshots))
+	d.Set("max_unique_snapshots", artifactory.IntValue(repo.MaxUniqueSnapshots))
+	d.Set("debian_trivial_layout", artifactory.BoolValue(repo.DebianTrivialLayout))
 	d.Set("checksum_policy_type", repo.ChecksumPolicyType)
-	d.Set("max_unique_tags", repo.MaxUniqueTags)
+	d.Set("max_unique_tags", artifactory.IntValue(repo.MaxUniqueTags))
 	d.Set("snapshot_version_behavior", repo.SnapshotVersionBehavior)
-	d.Set("suppress_pom_consistency_checks", repo.SuppressPomConsistencyChecks)
-	d.Set("blacked_out", repo.BlackedOut)
-	d.Set("archive_browsing_enabled", repo.ArchiveBrowsingEnabled)
-	d.Set("calculate_yum_metadata", repo.CalculateYumMetadata)
-	d.Set("yum_root_depth", repo.YumRootDepth)
+	d.Set("suppress_pom_consistency_checks", artifactory.BoolValue(repo.SuppressPomConsistencyChecks))
+	d.Set("blacked_out", artifactory.BoolValue(repo.BlackedOut))
+	d.Set("archive_browsing_enabled", artifactory.BoolValue(repo.ArchiveBrowsingEnabled))
+	d.Set("calculate_yum_metadata", artifactory.BoolValue(repo.CalculateYumMetadata))
+	d.Set("yum_root_depth", artifactory.IntValue(repo.YumRootDepth))
 	d.Set("docker_api_version", repo.DockerAPIVersion)
-	d.Set("enable_file_lists_indexing", repo.EnableFileListsIndexing)
+	d.Set("enable_file_lists_indexing", artifactory.BoolValue(repo.EnableFileListsIndexing))
 
 	props := make([]string, 0, len(repo.PropertySets))
 	for _, p := range repo.PropertySets {
@@ -225,29 +296,37 @@ func resourceLocalRepositoryRead(d *schema.ResourceData, m interface{}) error {
 	}
 	d.Set("property_sets", props)
 
-	return nil
+	formats := map[string]map[string]interface{}{
+		"alpine": {
+			"primary_keypair_ref": artifactory.StringValue(repo.PrimaryKeyPairRef),
+		},
+		"cargo": {
+			"anonymous_access": artifactory.BoolValue(repo.CargoAnonymousAccess),
+		},
+		"helm": {
+			"chart_version_suffix": artifactory.StringValue(repo.ChartVersionSuffix),
+		},
+		"terraform": {
+			"registry_type": artifactory.StringValue(repo.TerraformType),
+		},
+	}
+	for key, attrs := range formats {
+		if err := setFormatBlock(d, key, attrs); err != nil {
+			return err
+		}
+	}
+
+	return localRepositoryPackageTypeAttributes.reset(d, resourceLocalRepository().Schema, repo.PackageType)
 }
 
 func resourceLocalRepositoryUpdate(d *schema.ResourceData, m interface{}) error {
 	c := m.(artifactory.Client)
 	repo := newLocalRepositoryFromResource(d)
-	c.UpdateRepository(repo.Key, repo)
-
-	wait := repoCreateWait()
-	wait.Refresh = func() (interface{}, string, error) {
-		log.Printf("[DEBUG] Checking if Group %s is created", repo.Key)
-
-		newRepo := artifactory.LocalRepositoryConfiguration{}
-		err := c.GetRepository(repo.Key, &newRepo)
-		if err != nil {
-			return newRepo, "updating", err
-		}
-		log.Printf("[DEBUG] Group %s is created", repo.Key)
-		return newRepo, "updated", err
+	if err := c.UpdateRepository(repo.Key, repo); err != nil {
+		return err
 	}
 
-	_, err := wait.WaitForState()
-	if err != nil {
+	if err := waitForRepository(c, repo.Key, repo, d.Timeout(schema.TimeoutUpdate)); err != nil {
 		return err
 	}
 
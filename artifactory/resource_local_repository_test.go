@@ -1,6 +1,7 @@
 package artifactory
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -32,18 +33,17 @@ func TestAccLocalRepository_basic(t *testing.T) {
 const testAccLocalRepository_full = `
 resource "artifactory_local_repository" "foobar" {
     key                             = "acctest-local-full"
-    package_type                    = "npm"
+    package_type                    = "maven"
 	description                     = "desc"
 	notes                           = "the notes"
 	includes_pattern                = "**/*"
 	excludes_pattern                = "**/*.tgz"
-	repo_layout_ref                 = "npm-default"
+	repo_layout_ref                 = "maven-2-default"
 	handle_releases                 = true
 	handle_snapshots                = true
 	max_unique_snapshots            = 25
 	debian_trivial_layout           = false
 	checksum_policy_type            = "client-checksums"
-	max_unique_tags                 = 100
 	snapshot_version_behavior       = "unique"
 	suppress_pom_consistency_checks = true
 	blacked_out                     = false
@@ -64,18 +64,17 @@ func TestAccLocalRepository_full(t *testing.T) {
 				Config: testAccLocalRepository_full,
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "key", "acctest-local-full"),
-					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "package_type", "npm"),
+					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "package_type", "maven"),
 					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "description", "desc"),
 					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "notes", "the notes"),
 					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "includes_pattern", "**/*"),
 					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "excludes_pattern", "**/*.tgz"),
-					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "repo_layout_ref", "npm-default"),
+					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "repo_layout_ref", "maven-2-default"),
 					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "handle_releases", "true"),
 					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "handle_snapshots", "true"),
 					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "max_unique_snapshots", "25"),
 					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "debian_trivial_layout", "false"),
 					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "checksum_policy_type", "client-checksums"),
-					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "max_unique_tags", "100"),
 					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "snapshot_version_behavior", "unique"),
 					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "suppress_pom_consistency_checks", "true"),
 					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "blacked_out", "false"),
@@ -90,3 +89,327 @@ func TestAccLocalRepository_full(t *testing.T) {
 		},
 	})
 }
+
+func TestAccLocalRepository_removedOutOfBand(t *testing.T) {
+	testAccFakeOnly(t)
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy("artifactory_local_repository.foobar"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccLocalRepository_basic,
+			},
+			resource.TestStep{
+				PreConfig: func() { testAccFake.removeOutOfBand("repositories/acctest-local-basic") },
+				Config:    testAccLocalRepository_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "key", "acctest-local-basic"),
+				),
+			},
+		},
+	})
+}
+
+const testAccLocalRepository_invalidLayout = `
+resource "artifactory_local_repository" "foobar" {
+	key 	        = "acctest-local-basic"
+	package_type    = "docker"
+	repo_layout_ref = "acctest-no-such-layout"
+}`
+
+func TestAccLocalRepository_createError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccLocalRepository_invalidLayout,
+				ExpectError: regexp.MustCompile("400 Bad Request"),
+			},
+		},
+	})
+}
+
+func TestAccLocalRepository_updateError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy("artifactory_local_repository.foobar"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccLocalRepository_basic,
+			},
+			resource.TestStep{
+				Config:      testAccLocalRepository_invalidLayout,
+				ExpectError: regexp.MustCompile("400 Bad Request"),
+			},
+		},
+	})
+}
+
+func TestAccLocalRepository_eventuallyConsistent(t *testing.T) {
+	testAccFakeOnly(t)
+	defer testAccFake.reset()
+	// the repository is not visible to the first reads after it is created
+	testAccFake.fail("GET", "repositories/acctest-local-basic", 404, 3)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy("artifactory_local_repository.foobar"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccLocalRepository_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "key", "acctest-local-basic"),
+				),
+			},
+		},
+	})
+}
+
+const testAccLocalRepository_settingsOn = `
+resource "artifactory_local_repository" "foobar" {
+	key                  = "acctest-local-settings"
+	package_type         = "maven"
+	handle_snapshots     = true
+	blacked_out          = true
+	max_unique_snapshots = 10
+}`
+
+const testAccLocalRepository_settingsOff = `
+resource "artifactory_local_repository" "foobar" {
+	key                  = "acctest-local-settings"
+	package_type         = "maven"
+	handle_snapshots     = false
+	blacked_out          = false
+	max_unique_snapshots = 0
+}`
+
+func TestAccLocalRepository_turnSettingsOff(t *testing.T) {
+	resourceName := "artifactory_local_repository.foobar"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccLocalRepository_settingsOn,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "handle_snapshots", "true"),
+					resource.TestCheckResourceAttr(resourceName, "blacked_out", "true"),
+					resource.TestCheckResourceAttr(resourceName, "max_unique_snapshots", "10"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccLocalRepository_settingsOff,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "handle_snapshots", "false"),
+					resource.TestCheckResourceAttr(resourceName, "blacked_out", "false"),
+					resource.TestCheckResourceAttr(resourceName, "max_unique_snapshots", "0"),
+				),
+			},
+		},
+	})
+}
+
+const testAccLocalRepository_stringsSet = `
+resource "artifactory_local_repository" "foobar" {
+	key              = "acctest-local-strings"
+	description      = "hello"
+	notes            = "some notes"
+	excludes_pattern = "**/*.tmp"
+	property_sets    = [ "artifactory" ]
+}`
+
+const testAccLocalRepository_stringsCleared = `
+resource "artifactory_local_repository" "foobar" {
+	key = "acctest-local-strings"
+}`
+
+func TestAccLocalRepository_clearSettings(t *testing.T) {
+	resourceName := "artifactory_local_repository.foobar"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccLocalRepository_stringsSet,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "description", "hello"),
+					resource.TestCheckResourceAttr(resourceName, "notes", "some notes"),
+					resource.TestCheckResourceAttr(resourceName, "excludes_pattern", "**/*.tmp"),
+					resource.TestCheckResourceAttr(resourceName, "property_sets.#", "1"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccLocalRepository_stringsCleared,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "description", ""),
+					resource.TestCheckResourceAttr(resourceName, "notes", ""),
+					resource.TestCheckResourceAttr(resourceName, "excludes_pattern", ""),
+					resource.TestCheckResourceAttr(resourceName, "property_sets.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+const testAccLocalRepository_formats = `
+resource "artifactory_local_repository" "alpine" {
+	key          = "acctest-local-alpine"
+	package_type = "alpine"
+
+	alpine {
+		primary_keypair_ref = "acctest-keypair"
+	}
+}
+
+resource "artifactory_local_repository" "cargo" {
+	key          = "acctest-local-cargo"
+	package_type = "cargo"
+
+	cargo {
+		anonymous_access = true
+	}
+}
+
+resource "artifactory_local_repository" "helm" {
+	key          = "acctest-local-helm"
+	package_type = "helm"
+
+	helm {
+		chart_version_suffix = "-snapshot"
+	}
+}
+
+resource "artifactory_local_repository" "terraform" {
+	key          = "acctest-local-terraform"
+	package_type = "terraform"
+
+	terraform {
+		registry_type = "provider"
+	}
+}`
+
+const testAccLocalRepository_formatsUpdated = `
+resource "artifactory_local_repository" "alpine" {
+	key          = "acctest-local-alpine"
+	package_type = "alpine"
+}
+
+resource "artifactory_local_repository" "cargo" {
+	key          = "acctest-local-cargo"
+	package_type = "cargo"
+
+	cargo {
+		anonymous_access = false
+	}
+}
+
+resource "artifactory_local_repository" "helm" {
+	key          = "acctest-local-helm"
+	package_type = "helm"
+
+	helm {
+		chart_version_suffix = "-snapshot"
+	}
+}
+
+resource "artifactory_local_repository" "terraform" {
+	key          = "acctest-local-terraform"
+	package_type = "terraform"
+
+	terraform {
+		registry_type = "provider"
+	}
+}`
+
+func TestAccLocalRepository_formats(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy("artifactory_local_repository.cargo"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccLocalRepository_formats,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("artifactory_local_repository.alpine", "alpine.#", "1"),
+					resource.TestCheckResourceAttr("artifactory_local_repository.alpine", "alpine.0.primary_keypair_ref", "acctest-keypair"),
+					resource.TestCheckResourceAttr("artifactory_local_repository.alpine", "cargo.#", "0"),
+					resource.TestCheckResourceAttr("artifactory_local_repository.cargo", "cargo.0.anonymous_access", "true"),
+					resource.TestCheckResourceAttr("artifactory_local_repository.helm", "helm.0.chart_version_suffix", "-snapshot"),
+					resource.TestCheckResourceAttr("artifactory_local_repository.terraform", "terraform.0.registry_type", "provider"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      "artifactory_local_repository.terraform",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			resource.TestStep{
+				Config: testAccLocalRepository_formatsUpdated,
+				Check: resource.ComposeTestCheckFunc(
+					// removing the block unsets the key pair
+					resource.TestCheckResourceAttr("artifactory_local_repository.alpine", "alpine.#", "0"),
+					resource.TestCheckResourceAttr("artifactory_local_repository.cargo", "cargo.#", "1"),
+					resource.TestCheckResourceAttr("artifactory_local_repository.cargo", "cargo.0.anonymous_access", "false"),
+				),
+			},
+		},
+	})
+}
+
+const testAccLocalRepository_npm = `
+resource "artifactory_local_repository" "foobar" {
+	key          = "acctest-local-npm"
+	package_type = "npm"
+}`
+
+const testAccLocalRepository_npmWithYum = `
+resource "artifactory_local_repository" "foobar" {
+	key                    = "acctest-local-npm"
+	package_type           = "npm"
+	calculate_yum_metadata = true
+	max_unique_tags        = 10
+}
+
+resource "artifactory_local_repository" "other" {
+	key = "acctest-local-other"
+}`
+
+func TestAccLocalRepository_packageTypeAttributes(t *testing.T) {
+	resourceName := "artifactory_local_repository.foobar"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccLocalRepository_npm,
+				Check: resource.ComposeTestCheckFunc(
+					// the layout is chosen by Artifactory for the package type
+					resource.TestCheckResourceAttr(resourceName, "repo_layout_ref", "npm-default"),
+					resource.TestCheckResourceAttr(resourceName, "snapshot_version_behavior", "non-unique"),
+					resource.TestCheckResourceAttr(resourceName, "docker_api_version", "V2"),
+				),
+			},
+			resource.TestStep{
+				Config:      testAccLocalRepository_npmWithYum,
+				ExpectError: regexp.MustCompile("calculate_yum_metadata, max_unique_tags cannot be set on npm repositories"),
+			},
+			resource.TestStep{
+				// the configuration is rejected by the plan, before anything is applied
+				PreConfig: func() {
+					if testAccFake != nil && testAccFake.repositories["acctest-local-other"] != nil {
+						t.Error("expected acctest-local-other not to be created")
+					}
+				},
+				Config:   testAccLocalRepository_npm,
+				PlanOnly: true,
+			},
+		},
+	})
+}
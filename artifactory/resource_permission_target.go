@@ -0,0 +1,203 @@
+package artifactory
+
+import (
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+// permissionActions maps the action names used in configuration to the
+// single letter codes used by the Artifactory API
+var permissionActions = map[string]string{
+	"read":     artifactory.PermissionRead,
+	"annotate": artifactory.PermissionAnnotate,
+	"deploy":   artifactory.PermissionDeploy,
+	"delete":   artifactory.PermissionDelete,
+	"manage":   artifactory.PermissionManage,
+}
+
+func resourcePermissionTarget() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePermissionTargetCreate,
+		Read:   resourcePermissionTargetRead,
+		Update: resourcePermissionTargetUpdate,
+		Delete: resourcePermissionTargetDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"includes_pattern": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "**",
+			},
+			"excludes_pattern": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"repositories": &schema.Schema{
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+				Required: true,
+			},
+			"users":  permissionPrincipalSchema(),
+			"groups": permissionPrincipalSchema(),
+		},
+	}
+}
+
+func permissionPrincipalSchema() *schema.Schema {
+	actions := make([]string, 0, len(permissionActions))
+	for a := range permissionActions {
+		actions = append(actions, a)
+	}
+	sort.Strings(actions)
+
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": &schema.Schema{
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"permissions": &schema.Schema{
+					Type: schema.TypeSet,
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: validation.StringInSlice(actions, false),
+					},
+					Set:      schema.HashString,
+					Required: true,
+				},
+			},
+		},
+	}
+}
+
+func newPermissionTargetFromResource(d *schema.ResourceData) *artifactory.PermissionTarget {
+	repos := make([]string, 0, len(d.Get("repositories").(*schema.Set).List()))
+
+	for _, r := range d.Get("repositories").(*schema.Set).List() {
+		repos = append(repos, r.(string))
+	}
+
+	return &artifactory.PermissionTarget{
+		Name:            d.Get("name").(string),
+		IncludesPattern: d.Get("includes_pattern").(string),
+		ExcludesPattern: d.Get("excludes_pattern").(string),
+		Repositories:    repos,
+		Principals: artifactory.Principals{
+			Users:  expandPermissionPrincipals(d.Get("users").(*schema.Set)),
+			Groups: expandPermissionPrincipals(d.Get("groups").(*schema.Set)),
+		},
+	}
+}
+
+// expandPermissionPrincipals converts the users or groups blocks into the
+// principal to action code map sent to Artifactory
+func expandPermissionPrincipals(s *schema.Set) map[string][]string {
+	principals := make(map[string][]string, s.Len())
+
+	for _, v := range s.List() {
+		p := v.(map[string]interface{})
+		perms := p["permissions"].(*schema.Set).List()
+		codes := make([]string, 0, len(perms))
+		for _, perm := range perms {
+			codes = append(codes, permissionActions[perm.(string)])
+		}
+		principals[p["name"].(string)] = codes
+	}
+
+	return principals
+}
+
+// flattenPermissionPrincipals converts the principal to action code map read
+// from Artifactory back into users or groups blocks
+func flattenPermissionPrincipals(principals map[string][]string) []interface{} {
+	names := make(map[string]string, len(permissionActions))
+	for name, code := range permissionActions {
+		names[code] = name
+	}
+
+	l := make([]interface{}, 0, len(principals))
+	for principal, codes := range principals {
+		perms := make([]interface{}, 0, len(codes))
+		for _, code := range codes {
+			if name, ok := names[code]; ok {
+				perms = append(perms, name)
+			}
+		}
+		l = append(l, map[string]interface{}{
+			"name":        principal,
+			"permissions": schema.NewSet(schema.HashString, perms),
+		})
+	}
+
+	return l
+}
+
+func resourcePermissionTargetRead(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+
+	perm, err := c.GetPermissionTarget(d.Id())
+
+	if artifactory.IsNotFound(err) {
+		log.Printf("[WARN] Permission target %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	d.Set("name", perm.Name)
+	d.Set("includes_pattern", perm.IncludesPattern)
+	d.Set("excludes_pattern", perm.ExcludesPattern)
+	d.Set("repositories", perm.Repositories)
+	d.Set("users", flattenPermissionPrincipals(perm.Principals.Users))
+	d.Set("groups", flattenPermissionPrincipals(perm.Principals.Groups))
+
+	return nil
+}
+
+func resourcePermissionTargetCreate(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	perm := newPermissionTargetFromResource(d)
+	err := c.CreatePermissionTarget(perm)
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(perm.Name)
+	return resourcePermissionTargetRead(d, m)
+}
+
+func resourcePermissionTargetUpdate(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	perm := newPermissionTargetFromResource(d)
+	err := c.UpdatePermissionTarget(perm)
+
+	if err != nil {
+		return err
+	}
+
+	return resourcePermissionTargetRead(d, m)
+}
+
+func resourcePermissionTargetDelete(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	return c.DeletePermissionTarget(d.Id())
+}
@@ -0,0 +1,135 @@
+package artifactory
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+const testAccPermissionTarget_basic = `
+resource "artifactory_permission_target" "foobar" {
+	name         = "acctest-permission-basic"
+	repositories = [ "ANY" ]
+}`
+
+func TestAccPermissionTarget_basic(t *testing.T) {
+	resourceName := "artifactory_permission_target.foobar"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckPermissionTargetDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPermissionTarget_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", "acctest-permission-basic"),
+					resource.TestCheckResourceAttr(resourceName, "includes_pattern", "**"),
+					resource.TestCheckResourceAttr(resourceName, "repositories.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+const testAccPermissionTarget_escapedName = `
+resource "artifactory_permission_target" "foobar" {
+	name         = "acctest permission #1/deploy?"
+	repositories = [ "ANY" ]
+}`
+
+func TestAccPermissionTarget_escapedName(t *testing.T) {
+	resourceName := "artifactory_permission_target.foobar"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckPermissionTargetDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPermissionTarget_escapedName,
+				Check:  resource.TestCheckResourceAttr(resourceName, "name", "acctest permission #1/deploy?"),
+			},
+			resource.TestStep{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+const testAccPermissionTarget_full = `
+resource "artifactory_local_repository" "foobar" {
+	key = "acctest-permission-local"
+}
+
+resource "artifactory_group" "foobar" {
+	name = "acctest-permission-group"
+}
+
+resource "artifactory_user" "foobar" {
+	name  = "acctest-permission-user"
+	email = "acctest-permission-user@domain.com"
+}
+
+resource "artifactory_permission_target" "foobar" {
+	name             = "acctest-permission-full"
+	includes_pattern = "com/example/**"
+	excludes_pattern = "**/*.tmp"
+	repositories     = [
+		"${artifactory_local_repository.foobar.key}",
+		"ANY REMOTE"
+	]
+
+	users {
+		name        = "${artifactory_user.foobar.name}"
+		permissions = [ "read", "annotate", "deploy" ]
+	}
+
+	groups {
+		name        = "${artifactory_group.foobar.name}"
+		permissions = [ "read", "delete", "manage" ]
+	}
+}`
+
+func TestAccPermissionTarget_full(t *testing.T) {
+	resourceName := "artifactory_permission_target.foobar"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckPermissionTargetDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPermissionTarget_full,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", "acctest-permission-full"),
+					resource.TestCheckResourceAttr(resourceName, "includes_pattern", "com/example/**"),
+					resource.TestCheckResourceAttr(resourceName, "excludes_pattern", "**/*.tmp"),
+					resource.TestCheckResourceAttr(resourceName, "repositories.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "users.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "groups.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckPermissionTargetDestroy(id string) func(*terraform.State) error {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(artifactory.Client)
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found %s", id)
+		}
+
+		_, err := client.GetPermissionTarget(rs.Primary.ID)
+
+		if err == nil {
+			return fmt.Errorf("Permission target %s still exists", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
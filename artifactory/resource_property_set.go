@@ -0,0 +1,203 @@
+package artifactory
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+func resourcePropertySet() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePropertySetCreate,
+		Read:   resourcePropertySetRead,
+		Update: resourcePropertySetUpdate,
+		Delete: resourcePropertySetDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"visible": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"property": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"predefined_value": &schema.Schema{
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     propertySetPredefinedValueResource(),
+						},
+						"closed_predefined_values": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"multiple_choice": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func propertySetPredefinedValueResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"value": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"default_value": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+	}
+}
+
+func newPropertySetFromResource(d *schema.ResourceData) (*artifactory.PropertySet, error) {
+	ps := &artifactory.PropertySet{
+		Name:    d.Get("name").(string),
+		Visible: d.Get("visible").(bool),
+	}
+
+	for _, v := range d.Get("property").(*schema.Set).List() {
+		p := v.(map[string]interface{})
+		prop := artifactory.Property{
+			Name:                   p["name"].(string),
+			ClosedPredefinedValues: p["closed_predefined_values"].(bool),
+			MultipleChoice:         p["multiple_choice"].(bool),
+		}
+
+		if prop.MultipleChoice && !prop.ClosedPredefinedValues {
+			return nil, fmt.Errorf("Property %s: multiple_choice requires closed_predefined_values", prop.Name)
+		}
+
+		for _, pv := range p["predefined_value"].(*schema.Set).List() {
+			value := pv.(map[string]interface{})
+			prop.PredefinedValues = append(prop.PredefinedValues, artifactory.PredefinedValue{
+				Value:        value["value"].(string),
+				DefaultValue: value["default_value"].(bool),
+			})
+		}
+
+		ps.Properties = append(ps.Properties, prop)
+	}
+
+	return ps, nil
+}
+
+func flattenPropertySetProperties(properties []artifactory.Property) []interface{} {
+	l := make([]interface{}, 0, len(properties))
+	hashValue := schema.HashResource(propertySetPredefinedValueResource())
+
+	for _, p := range properties {
+		values := make([]interface{}, 0, len(p.PredefinedValues))
+		for _, v := range p.PredefinedValues {
+			values = append(values, map[string]interface{}{
+				"value":         v.Value,
+				"default_value": v.DefaultValue,
+			})
+		}
+
+		l = append(l, map[string]interface{}{
+			"name":                     p.Name,
+			"predefined_value":         schema.NewSet(hashValue, values),
+			"closed_predefined_values": p.ClosedPredefinedValues,
+			"multiple_choice":          p.MultipleChoice,
+		})
+	}
+
+	return l
+}
+
+func resourcePropertySetRead(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+
+	ps, err := c.GetPropertySet(d.Id())
+
+	if artifactory.IsNotFound(err) {
+		log.Printf("[WARN] Property set %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	d.Set("name", ps.Name)
+	d.Set("visible", ps.Visible)
+	if err := d.Set("property", flattenPropertySetProperties(ps.Properties)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func resourcePropertySetCreate(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	ps, err := newPropertySetFromResource(d)
+	if err != nil {
+		return err
+	}
+
+	// saving a property set replaces the one with the same name, which may be in use
+	_, err = c.GetPropertySet(ps.Name)
+	if err == nil {
+		return fmt.Errorf("property set %s already exists, import it to manage it", ps.Name)
+	}
+	if !artifactory.IsNotFound(err) {
+		return err
+	}
+
+	err = c.SavePropertySet(ps)
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(ps.Name)
+	return resourcePropertySetRead(d, m)
+}
+
+func resourcePropertySetUpdate(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	ps, err := newPropertySetFromResource(d)
+	if err != nil {
+		return err
+	}
+
+	err = c.SavePropertySet(ps)
+
+	if err != nil {
+		return err
+	}
+
+	return resourcePropertySetRead(d, m)
+}
+
+func resourcePropertySetDelete(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	return c.DeletePropertySet(d.Id())
+}
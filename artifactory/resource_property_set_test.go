@@ -0,0 +1,271 @@
+package artifactory
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+const testAccPropertySet_full = `
+resource "artifactory_property_set" "foobar" {
+	name = "acctest-league"
+
+	property {
+		name                     = "lane"
+		closed_predefined_values = true
+
+		predefined_value {
+			value         = "1"
+			default_value = true
+		}
+
+		predefined_value {
+			value = "2"
+		}
+	}
+
+	property {
+		name                     = "bowlers"
+		closed_predefined_values = true
+		multiple_choice          = true
+
+		predefined_value {
+			value = "the.dude"
+		}
+
+		predefined_value {
+			value = "walter"
+		}
+
+		predefined_value {
+			value = "donny"
+		}
+	}
+
+	property {
+		name = "notes"
+	}
+}
+
+resource "artifactory_local_repository" "foobar" {
+	key           = "acctest-league-local"
+	package_type  = "generic"
+	property_sets = [ "${artifactory_property_set.foobar.name}" ]
+}`
+
+const testAccPropertySet_updated = `
+resource "artifactory_property_set" "foobar" {
+	name    = "acctest-league"
+	visible = false
+
+	property {
+		name                     = "bowlers"
+		closed_predefined_values = true
+		multiple_choice          = true
+
+		predefined_value {
+			value = "the.dude"
+		}
+
+		predefined_value {
+			value = "walter"
+		}
+	}
+
+	property {
+		name = "notes"
+	}
+}`
+
+func TestAccPropertySet_full(t *testing.T) {
+	resourceName := "artifactory_property_set.foobar"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckPropertySetDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPropertySet_full,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", "acctest-league"),
+					resource.TestCheckResourceAttr(resourceName, "visible", "true"),
+					resource.TestCheckResourceAttr(resourceName, "property.#", "3"),
+					testAccCheckPropertySet("acctest-league", map[string][]string{
+						"bowlers": {"donny", "the.dude", "walter"},
+						"lane":    {"1", "2"},
+						"notes":   nil,
+					}),
+				),
+			},
+			resource.TestStep{
+				// properties and values that are removed are deleted, as the configuration is merged
+				Config: testAccPropertySet_updated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "visible", "false"),
+					resource.TestCheckResourceAttr(resourceName, "property.#", "2"),
+					testAccCheckPropertySet("acctest-league", map[string][]string{
+						"bowlers": {"the.dude", "walter"},
+						"notes":   nil,
+					}),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccPropertySet_drift(t *testing.T) {
+	resourceName := "artifactory_property_set.foobar"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckPropertySetDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPropertySet_updated,
+			},
+			resource.TestStep{
+				PreConfig: func() {
+					client := testAccProvider.Meta().(artifactory.Client)
+					ps, _ := client.GetPropertySet("acctest-league")
+					ps.Properties[0].PredefinedValues = append(ps.Properties[0].PredefinedValues, artifactory.PredefinedValue{Value: "jesus"})
+					client.SavePropertySet(ps)
+				},
+				Config:             testAccPropertySet_updated,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			resource.TestStep{
+				Config: testAccPropertySet_updated,
+			},
+			resource.TestStep{
+				PreConfig: func() {
+					testAccProvider.Meta().(artifactory.Client).DeletePropertySet("acctest-league")
+				},
+				Config:             testAccPropertySet_updated,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			resource.TestStep{
+				Config: testAccPropertySet_updated,
+				Check: testAccCheckPropertySet("acctest-league", map[string][]string{
+					"bowlers": {"the.dude", "walter"},
+					"notes":   nil,
+				}),
+			},
+		},
+	})
+}
+
+const testAccPropertySet_multipleChoiceOpen = `
+resource "artifactory_property_set" "foobar" {
+	name = "acctest-league"
+
+	property {
+		name            = "bowlers"
+		multiple_choice = true
+	}
+}`
+
+func TestAccPropertySet_multipleChoiceOpen(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccPropertySet_multipleChoiceOpen,
+				ExpectError: regexp.MustCompile("multiple_choice requires closed_predefined_values"),
+			},
+		},
+	})
+}
+
+const testAccPropertySet_existing = `
+resource "artifactory_property_set" "foobar" {
+	name = "artifactory"
+
+	property {
+		name = "bowlers"
+	}
+}`
+
+func TestAccPropertySet_existing(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		CheckDestroy: func(s *terraform.State) error {
+			// the built-in property set is left as it was
+			client := testAccProvider.Meta().(artifactory.Client)
+			ps, err := client.GetPropertySet("artifactory")
+			if err != nil {
+				return err
+			}
+			for _, p := range ps.Properties {
+				if p.Name == "bowlers" {
+					return fmt.Errorf("Property set artifactory was overwritten")
+				}
+			}
+			return nil
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccPropertySet_existing,
+				ExpectError: regexp.MustCompile("property set artifactory already exists, import it to manage it"),
+			},
+		},
+	})
+}
+
+// testAccCheckPropertySet checks the predefined values of each property of a property set
+func testAccCheckPropertySet(name string, expected map[string][]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(artifactory.Client)
+		ps, err := client.GetPropertySet(name)
+		if err != nil {
+			return err
+		}
+
+		got := make(map[string][]string)
+		for _, p := range ps.Properties {
+			var values []string
+			for _, v := range p.PredefinedValues {
+				values = append(values, v.Value)
+			}
+			got[p.Name] = values
+		}
+
+		if !reflect.DeepEqual(got, expected) {
+			return fmt.Errorf("expected property set %s to have %v, got %v", name, expected, got)
+		}
+		return nil
+	}
+}
+
+func testAccCheckPropertySetDestroy(id string) func(*terraform.State) error {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(artifactory.Client)
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found %s", id)
+		}
+
+		_, err := client.GetPropertySet(rs.Primary.ID)
+
+		if err == nil {
+			return fmt.Errorf("Property set %s still exists", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
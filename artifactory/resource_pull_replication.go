@@ -0,0 +1,124 @@
+package artifactory
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+func resourcePullReplication() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePullReplicationCreate,
+		Read:   resourcePullReplicationRead,
+		Update: resourcePullReplicationUpdate,
+		Delete: resourceReplicationDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: map[string]*schema.Schema{
+			"repo_key": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"cron_exp": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"enable_event_replication": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"sync_deletes": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"sync_properties": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"path_prefix": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+func newPullReplicationFromResource(d *schema.ResourceData) *artifactory.Replication {
+	return &artifactory.Replication{
+		RepoKey:                d.Get("repo_key").(string),
+		CronExp:                d.Get("cron_exp").(string),
+		EnableEventReplication: d.Get("enable_event_replication").(bool),
+		Enabled:                d.Get("enabled").(bool),
+		SyncDeletes:            d.Get("sync_deletes").(bool),
+		SyncProperties:         d.Get("sync_properties").(bool),
+		PathPrefix:             d.Get("path_prefix").(string),
+	}
+}
+
+func resourcePullReplicationRead(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+
+	replications, err := c.GetReplications(d.Id())
+
+	if artifactory.IsNotFound(err) || (err == nil && len(replications) == 0) {
+		log.Printf("[WARN] Pull replication of %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(replications) != 1 {
+		return fmt.Errorf("Expected a single pull replication for '%s', got %d", d.Id(), len(replications))
+	}
+
+	r := replications[0]
+	d.Set("repo_key", d.Id())
+	d.Set("cron_exp", r.CronExp)
+	d.Set("enable_event_replication", r.EnableEventReplication)
+	d.Set("enabled", r.Enabled)
+	d.Set("sync_deletes", r.SyncDeletes)
+	d.Set("sync_properties", r.SyncProperties)
+	d.Set("path_prefix", r.PathPrefix)
+
+	return nil
+}
+
+func resourcePullReplicationCreate(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	replication := newPullReplicationFromResource(d)
+	err := c.CreatePullReplication(replication)
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(replication.RepoKey)
+	return resourcePullReplicationRead(d, m)
+}
+
+func resourcePullReplicationUpdate(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	replication := newPullReplicationFromResource(d)
+	err := c.UpdatePullReplication(replication)
+
+	if err != nil {
+		return err
+	}
+
+	return resourcePullReplicationRead(d, m)
+}
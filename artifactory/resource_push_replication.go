@@ -0,0 +1,248 @@
+package artifactory
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+func resourcePushReplication() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePushReplicationCreate,
+		Read:   resourcePushReplicationRead,
+		Update: resourcePushReplicationUpdate,
+		Delete: resourceReplicationDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		CustomizeDiff: resourcePushReplicationCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"repo_key": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"cron_exp": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"enable_event_replication": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"replications": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"url": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"socket_timeout_millis": &schema.Schema{
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  15000,
+						},
+						"username": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"password": &schema.Schema{
+							Type:      schema.TypeString,
+							Optional:  true,
+							Sensitive: true,
+							StateFunc: hashPassword,
+						},
+						"password_wo": &schema.Schema{
+							Type:             schema.TypeString,
+							Optional:         true,
+							Sensitive:        true,
+							DiffSuppressFunc: resourcePushReplicationPasswordWriteOnlyDiffSuppress,
+						},
+						"password_wo_version": &schema.Schema{
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+						"enabled": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+						"sync_deletes": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"sync_properties": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+						"sync_statistics": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"path_prefix": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newPushReplicationFromResource(d *schema.ResourceData) *artifactory.ReplicationConfig {
+	config := &artifactory.ReplicationConfig{
+		RepoKey:                d.Get("repo_key").(string),
+		CronExp:                d.Get("cron_exp").(string),
+		EnableEventReplication: d.Get("enable_event_replication").(bool),
+	}
+
+	l := d.Get("replications").([]interface{})
+	config.Replications = make([]artifactory.Replication, 0, len(l))
+	for i, v := range l {
+		r := v.(map[string]interface{})
+
+		// the state only holds a hash of the password, or nothing at all when it
+		// is write-only, so it is only sent when it changes
+		var password string
+		if d.HasChange(fmt.Sprintf("replications.%d.password", i)) {
+			password = r["password"].(string)
+		}
+		if d.HasChange(fmt.Sprintf("replications.%d.password_wo", i)) {
+			password = r["password_wo"].(string)
+		}
+
+		config.Replications = append(config.Replications, artifactory.Replication{
+			URL:                    r["url"].(string),
+			SocketTimeoutMillis:    r["socket_timeout_millis"].(int),
+			Username:               r["username"].(string),
+			Password:               password,
+			EnableEventReplication: config.EnableEventReplication,
+			Enabled:                r["enabled"].(bool),
+			CronExp:                config.CronExp,
+			SyncDeletes:            r["sync_deletes"].(bool),
+			SyncProperties:         r["sync_properties"].(bool),
+			SyncStatistics:         r["sync_statistics"].(bool),
+			RepoKey:                config.RepoKey,
+			PathPrefix:             r["path_prefix"].(string),
+		})
+	}
+
+	return config
+}
+
+func resourcePushReplicationRead(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+
+	replications, err := c.GetReplications(d.Id())
+
+	if artifactory.IsNotFound(err) || (err == nil && len(replications) == 0) {
+		log.Printf("[WARN] Push replication of %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Artifactory only returns the password in encrypted form, so the hash of
+	// the configured password of each target is kept, and the write-only one is
+	// never stored. A password applied just now is still in plain text.
+	passwords := make(map[string]string)
+	versions := make(map[string]int)
+	for i, v := range d.Get("replications").([]interface{}) {
+		r := v.(map[string]interface{})
+		password := r["password"].(string)
+		if d.HasChange(fmt.Sprintf("replications.%d.password", i)) {
+			password = hashPassword(password)
+		}
+		passwords[r["url"].(string)] = password
+		versions[r["url"].(string)] = r["password_wo_version"].(int)
+	}
+
+	l := make([]interface{}, 0, len(replications))
+	for _, r := range replications {
+		l = append(l, map[string]interface{}{
+			"url":                   r.URL,
+			"socket_timeout_millis": r.SocketTimeoutMillis,
+			"username":              r.Username,
+			"password":              passwords[r.URL],
+			"password_wo":           "",
+			"password_wo_version":   versions[r.URL],
+			"enabled":               r.Enabled,
+			"sync_deletes":          r.SyncDeletes,
+			"sync_properties":       r.SyncProperties,
+			"sync_statistics":       r.SyncStatistics,
+			"path_prefix":           r.PathPrefix,
+		})
+	}
+
+	d.Set("repo_key", d.Id())
+	d.Set("cron_exp", replications[0].CronExp)
+	d.Set("enable_event_replication", replications[0].EnableEventReplication)
+	d.Set("replications", l)
+
+	return nil
+}
+
+func resourcePushReplicationCreate(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	config := newPushReplicationFromResource(d)
+	err := c.CreatePushReplication(config)
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(config.RepoKey)
+	return resourcePushReplicationRead(d, m)
+}
+
+func resourcePushReplicationUpdate(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	config := newPushReplicationFromResource(d)
+	err := c.UpdatePushReplication(config)
+
+	if err != nil {
+		return err
+	}
+
+	return resourcePushReplicationRead(d, m)
+}
+
+// resourcePushReplicationCustomizeDiff rejects targets setting both passwords
+func resourcePushReplicationCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	for i, v := range d.Get("replications").([]interface{}) {
+		r := v.(map[string]interface{})
+		if r["password"].(string) != "" && r["password_wo"].(string) != "" {
+			return fmt.Errorf("replications.%d: password conflicts with password_wo", i)
+		}
+	}
+	return nil
+}
+
+// resourcePushReplicationPasswordWriteOnlyDiffSuppress only lets the
+// write-only password of a target through when the target is added, or when
+// its password_wo_version changes. It is never stored, so it always differs
+// from the state otherwise.
+func resourcePushReplicationPasswordWriteOnlyDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	prefix := strings.TrimSuffix(k, "password_wo")
+	url, _ := d.GetChange(prefix + "url")
+	return url.(string) != "" && !d.HasChange(prefix+"password_wo_version")
+}
+
+func resourceReplicationDelete(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	return c.DeleteReplications(d.Id())
+}
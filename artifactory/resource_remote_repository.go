@@ -1,8 +1,10 @@
 package artifactory
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -19,6 +21,11 @@ func resourceRemoteRepository() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+		},
+		CustomizeDiff: remoteRepositoryPackageTypeAttributes.customizeDiff(resourceRemoteRepository),
 		Schema: map[string]*schema.Schema{
 			"key": &schema.Schema{
 				Type:     schema.TypeString,
@@ -53,7 +60,7 @@ func resourceRemoteRepository() *schema.Resource {
 			"repo_layout_ref": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
-				Default:  "maven-2-default",
+				Computed: true,
 			},
 			"handle_releases": &schema.Schema{
 				Type:     schema.TypeBool,
@@ -82,7 +89,21 @@ func resourceRemoteRepository() *schema.Resource {
 				Optional: true,
 			},
 			"password": &schema.Schema{
-				Type:     schema.TypeString,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				StateFunc:     hashPassword,
+				ConflictsWith: []string{"password_wo"},
+			},
+			"password_wo": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				DiffSuppressFunc: resourceRemotePasswordWriteOnlyDiffSuppress,
+				ConflictsWith:    []string{"password"},
+			},
+			"password_wo_version": &schema.Schema{
+				Type:     schema.TypeInt,
 				Optional: true,
 			},
 			"proxy": &schema.Schema{
@@ -191,57 +212,146 @@ func resourceRemoteRepository() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"cargo": repositoryFormatSchema(map[string]*schema.Schema{
+				"git_registry_url": &schema.Schema{
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"anonymous_access": &schema.Schema{
+					Type:     schema.TypeBool,
+					Optional: true,
+				},
+			}),
+			"cocoapods": repositoryFormatSchema(map[string]*schema.Schema{
+				"specs_repo_url": &schema.Schema{
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			}),
+			"go": repositoryFormatSchema(map[string]*schema.Schema{
+				"vcs_download_url": &schema.Schema{
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			}),
+			"helm": repositoryFormatSchema(map[string]*schema.Schema{
+				"charts_base_url": &schema.Schema{
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			}),
+			"terraform": repositoryFormatSchema(map[string]*schema.Schema{
+				"registry_url": &schema.Schema{
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"providers_url": &schema.Schema{
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			}),
 		},
 	}
 }
 
+// remoteRepositoryPackageTypeAttributes are the attributes of remote
+// repositories that only apply to some package types
+var remoteRepositoryPackageTypeAttributes = packageTypeAttributes{
+	"max_unique_snapshots":            mavenPackageTypes,
+	"suppress_pom_consistency_checks": mavenPackageTypes,
+	"fetch_jars_eagerly":              mavenPackageTypes,
+	"fetch_sources_eagerly":           mavenPackageTypes,
+	"bower_registry_url":              {"bower"},
+	"vcs_type":                        {"bower", "cocoapods", "composer", "go"},
+	"vcs_git_provider":                {"bower", "cocoapods", "composer", "go"},
+	"vcs_git_download_url":            {"bower", "cocoapods", "composer", "go"},
+	"cargo":                           {"cargo"},
+	"cocoapods":                       {"cocoapods"},
+	"go":                              {"go"},
+	"helm":                            {"helm"},
+	"terraform":                       {"terraform"},
+}
+
 func newRemoteRepositoryFromResource(d *schema.ResourceData) *artifactory.RemoteRepositoryConfiguration {
 	props := make([]string, 0, len(d.Get("property_sets").(*schema.Set).List()))
 
 	for _, p := range d.Get("property_sets").(*schema.Set).List() {
 		props = append(props, p.(string))
 	}
-	return &artifactory.RemoteRepositoryConfiguration{
+
+	repo := &artifactory.RemoteRepositoryConfiguration{
 		Key:                               d.Get("key").(string),
 		RClass:                            "remote",
 		PackageType:                       d.Get("package_type").(string),
 		URL:                               d.Get("url").(string),
 		Username:                          d.Get("username").(string),
-		Password:                          d.Get("password").(string),
 		Proxy:                             d.Get("proxy").(string),
 		Description:                       d.Get("description").(string),
 		Notes:                             d.Get("notes").(string),
 		IncludesPattern:                   d.Get("includes_pattern").(string),
 		ExcludesPattern:                   d.Get("excludes_pattern").(string),
 		RepoLayoutRef:                     d.Get("repo_layout_ref").(string),
-		HandleReleases:                    d.Get("handle_releases").(bool),
-		HandleSnapshots:                   d.Get("handle_snapshots").(bool),
-		MaxUniqueSnapshots:                d.Get("max_unique_snapshots").(int),
-		SuppressPomConsistencyChecks:      d.Get("suppress_pom_consistency_checks").(bool),
+		HandleReleases:                    getBoolRef(d, "handle_releases"),
+		HandleSnapshots:                   getBoolRef(d, "handle_snapshots"),
+		MaxUniqueSnapshots:                getIntRef(d, "max_unique_snapshots"),
+		SuppressPomConsistencyChecks:      getBoolRef(d, "suppress_pom_consistency_checks"),
 		RemoteRepoChecksumPolicyType:      d.Get("remote_repo_checksum_policy_type").(string),
-		HardFail:                          d.Get("hard_fail").(bool),
-		Offline:                           d.Get("offline").(bool),
-		BlackedOut:                        d.Get("blacked_out").(bool),
-		StoreArtifactsLocally:             d.Get("store_artifacts_locally").(bool),
-		SocketTimeoutMillis:               d.Get("socket_timeout_millis").(int),
+		HardFail:                          getBoolRef(d, "hard_fail"),
+		Offline:                           getBoolRef(d, "offline"),
+		BlackedOut:                        getBoolRef(d, "blacked_out"),
+		StoreArtifactsLocally:             getBoolRef(d, "store_artifacts_locally"),
+		SocketTimeoutMillis:               getIntRef(d, "socket_timeout_millis"),
 		LocalAddress:                      d.Get("local_address").(string),
-		RetrievalCachePeriodSeconds:       d.Get("retrieval_cache_period_seconds").(int),
-		FailedCachePeriodSeconds:          d.Get("failed_cache_period_seconds").(int),
-		MissedCachePeriodSeconds:          d.Get("missed_cache_period_seconds").(int),
-		UnusedArtifactsCleanupEnabled:     d.Get("unused_artifacts_cleanup_enabled").(bool),
-		UnusedArtifactsCleanupPeriodHours: d.Get("unused_artifacts_cleanup_period_hours").(int),
-		FetchJarsEagerly:                  d.Get("fetch_jars_eagerly").(bool),
-		FetchSourcesEagerly:               d.Get("fetch_sources_eagerly").(bool),
-		ShareConfiguration:                d.Get("share_configuration").(bool),
-		SynchronizeProperties:             d.Get("synchronize_properties").(bool),
+		RetrievalCachePeriodSeconds:       getIntRef(d, "retrieval_cache_period_seconds"),
+		FailedCachePeriodSeconds:          getIntRef(d, "failed_cache_period_seconds"),
+		MissedCachePeriodSeconds:          getIntRef(d, "missed_cache_period_seconds"),
+		UnusedArtifactsCleanupEnabled:     getBoolRef(d, "unused_artifacts_cleanup_enabled"),
+		UnusedArtifactsCleanupPeriodHours: getIntRef(d, "unused_artifacts_cleanup_period_hours"),
+		FetchJarsEagerly:                  getBoolRef(d, "fetch_jars_eagerly"),
+		FetchSourcesEagerly:               getBoolRef(d, "fetch_sources_eagerly"),
+		ShareConfiguration:                getBoolRef(d, "share_configuration"),
+		SynchronizeProperties:             getBoolRef(d, "synchronize_properties"),
 		PropertySets:                      props,
-		AllowAnyHostAuth:                  d.Get("allow_any_host_auth").(bool),
-		EnableCookieManagement:            d.Get("enable_cookie_management").(bool),
+		AllowAnyHostAuth:                  getBoolRef(d, "allow_any_host_auth"),
+		EnableCookieManagement:            getBoolRef(d, "enable_cookie_management"),
 		BowerRegistryURL:                  d.Get("bower_registry_url").(string),
 		VCSType:                           d.Get("vcs_type").(string),
 		VCSGitProvider:                    d.Get("vcs_git_provider").(string),
 		VCSGitDownloadURL:                 d.Get("vcs_git_download_url").(string),
 	}
+
+	// the state only holds a hash of the password, or nothing at all when it
+	// is write-only, so it is only sent when it changes
+	if d.HasChange("password") {
+		repo.Password = d.Get("password").(string)
+	}
+	if d.HasChange("password_wo") {
+		repo.Password = d.Get("password_wo").(string)
+	}
+
+	if cargo := getFormatBlock(d, "cargo"); cargo != nil {
+		repo.GitRegistryURL = artifactory.String(cargo["git_registry_url"].(string))
+		repo.CargoAnonymousAccess = artifactory.Bool(cargo["anonymous_access"].(bool))
+	}
+
+	if cocoapods := getFormatBlock(d, "cocoapods"); cocoapods != nil {
+		repo.PodsSpecsRepoURL = artifactory.String(cocoapods["specs_repo_url"].(string))
+	}
+
+	if goBlock := getFormatBlock(d, "go"); goBlock != nil {
+		repo.VCSDownloadURL = artifactory.String(goBlock["vcs_download_url"].(string))
+	}
+
+	if helm := getFormatBlock(d, "helm"); helm != nil {
+		repo.ChartsBaseURL = artifactory.String(helm["charts_base_url"].(string))
+	}
+
+	if terraform := getFormatBlock(d, "terraform"); terraform != nil {
+		repo.TerraformRegistryURL = artifactory.String(terraform["registry_url"].(string))
+		repo.TerraformProvidersURL = artifactory.String(terraform["providers_url"].(string))
+	}
+
+	return repo
 }
 
 func resourceRemoteRepositoryCreate(d *schema.ResourceData, m interface{}) error {
@@ -255,7 +365,12 @@ func resourceRemoteRepositoryCreate(d *schema.ResourceData, m interface{}) error
 	}
 
 	d.SetId(repo.Key)
-	return resourceRemoteRepositoryUpdate(d, m)
+
+	if err := waitForRepository(c, repo.Key, repo, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return err
+	}
+
+	return resourceRemoteRepositoryRead(d, m)
 }
 
 func resourceRemoteRepositoryRead(d *schema.ResourceData, m interface{}) error {
@@ -265,6 +380,12 @@ func resourceRemoteRepositoryRead(d *schema.ResourceData, m interface{}) error {
 
 	err := c.GetRepository(key, &repo)
 
+	if artifactory.IsNotFound(err) {
+		log.Printf("[WARN] Remote repository %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
 	if err != nil {
 		return err
 	}
@@ -274,34 +395,36 @@ func resourceRemoteRepositoryRead(d *schema.ResourceData, m interface{}) error {
 	d.Set("package_type", repo.PackageType)
 	d.Set("url", repo.URL)
 	d.Set("username", repo.Username)
-	d.Set("password", repo.Password)
+	// Artifactory only returns the password in encrypted form, so the hash of
+	// the configured password is kept, and the write-only one is never stored
+	d.Set("password_wo", "")
 	d.Set("proxy", repo.Proxy)
 	d.Set("description", repo.Description)
 	d.Set("notes", repo.Notes)
 	d.Set("includes_pattern", repo.IncludesPattern)
 	d.Set("excludes_pattern", repo.ExcludesPattern)
 	d.Set("repo_layout_ref", repo.RepoLayoutRef)
-	d.Set("handle_releases", repo.HandleReleases)
-	d.Set("handle_snapshots", repo.HandleSnapshots)
-	d.Set("max_unique_snapshots", repo.MaxUniqueSnapshots)
+	d.Set("handle_releases", artifactory.BoolValue(repo.HandleReleases))
+	d.Set("handle_snapshots", artifactory.BoolValue(repo.HandleSnapshots))
+	d.Set("max_unique_snapshots", artifactory.IntValue(repo.MaxUniqueSnapshots))
 	d.Set("remote_repo_checksum_policy_type", repo.RemoteRepoChecksumPolicyType)
-	d.Set("hard_fail", repo.HardFail)
-	d.Set("offline", repo.Offline)
-	d.Set("blacked_out", repo.BlackedOut)
-	d.Set("store_artifacts_locally", repo.StoreArtifactsLocally)
-	d.Set("socket_timeout_millis", repo.SocketTimeoutMillis)
+	d.Set("hard_fail", artifactory.BoolValue(repo.HardFail))
+	d.Set("offline", artifactory.BoolValue(repo.Offline))
+	d.Set("blacked_out", artifactory.BoolValue(repo.BlackedOut))
+	d.Set("store_artifacts_locally", artifactory.BoolValue(repo.StoreArtifactsLocally))
+	d.Set("socket_timeout_millis", artifactory.IntValue(repo.SocketTimeoutMillis))
 	d.Set("local_address", repo.LocalAddress)
-	d.Set("retrieval_cache_period_seconds", repo.RetrievalCachePeriodSeconds)
-	d.Set("failed_cache_period_seconds", repo.FailedCachePeriodSeconds)
-	d.Set("missed_cache_period_seconds", repo.MissedCachePeriodSeconds)
-	d.Set("unused_artifacts_cleanup_enabled", repo.UnusedArtifactsCleanupEnabled)
-	d.Set("unused_artifacts_cleanup_period_hours", repo.UnusedArtifactsCleanupPeriodHours)
-	d.Set("fetch_jars_eagerly", repo.FetchJarsEagerly)
-	d.Set("fetch_sources_eagerly", repo.FetchSourcesEagerly)
-	d.Set("share_configuration", repo.ShareConfiguration)
-	d.Set("synchronize_properties", repo.SynchronizeProperties)
-	d.Set("allow_any_host_auth", repo.AllowAnyHostAuth)
-	d.Set("enable_cookie_management", repo.EnableCookieManagement)
+	d.Set("retrieval_cache_period_seconds", artifactory.IntValue(repo.RetrievalCachePeriodSeconds))
+	d.Set("failed_cache_period_seconds", artifactory.IntValue(repo.FailedCachePeriodSeconds))
+	d.Set("missed_cache_period_seconds", artifactory.IntValue(repo.MissedCachePeriodSeconds))
+	d.Set("unused_artifacts_cleanup_enabled", artifactory.BoolValue(repo.UnusedArtifactsCleanupEnabled))
+	d.Set("unused_artifacts_cleanup_period_hours", artifactory.IntValue(repo.UnusedArtifactsCleanupPeriodHours))
+	d.Set("fetch_jars_eagerly", artifactory.BoolValue(repo.FetchJarsEagerly))
+	d.Set("fetch_sources_eagerly", artifactory.BoolValue(repo.FetchSourcesEagerly))
+	d.Set("share_configuration", artifactory.BoolValue(repo.ShareConfiguration))
+	d.Set("synchronize_properties", artifactory.BoolValue(repo.SynchronizeProperties))
+	d.Set("allow_any_host_auth", artifactory.BoolValue(repo.AllowAnyHostAuth))
+	d.Set("enable_cookie_management", artifactory.BoolValue(repo.EnableCookieManagement))
 	d.Set("bower_registry_url", repo.BowerRegistryURL)
 	d.Set("vcs_type", repo.VCSType)
 	d.Set("vcs_git_provider", repo.VCSGitProvider)
@@ -313,29 +436,42 @@ func resourceRemoteRepositoryRead(d *schema.ResourceData, m interface{}) error {
 	}
 	d.Set("property_sets", props)
 
-	return nil
+	formats := map[string]map[string]interface{}{
+		"cargo": {
+			"git_registry_url": artifactory.StringValue(repo.GitRegistryURL),
+			"anonymous_access": artifactory.BoolValue(repo.CargoAnonymousAccess),
+		},
+		"cocoapods": {
+			"specs_repo_url": artifactory.StringValue(repo.PodsSpecsRepoURL),
+		},
+		"go": {
+			"vcs_download_url": artifactory.StringValue(repo.VCSDownloadURL),
+		},
+		"helm": {
+			"charts_base_url": artifactory.StringValue(repo.ChartsBaseURL),
+		},
+		"terraform": {
+			"registry_url":  artifactory.StringValue(repo.TerraformRegistryURL),
+			"providers_url": artifactory.StringValue(repo.TerraformProvidersURL),
+		},
+	}
+	for key, attrs := range formats {
+		if err := setFormatBlock(d, key, attrs); err != nil {
+			return err
+		}
+	}
+
+	return remoteRepositoryPackageTypeAttributes.reset(d, resourceRemoteRepository().Schema, repo.PackageType)
 }
 
 func resourceRemoteRepositoryUpdate(d *schema.ResourceData, m interface{}) error {
 	c := m.(artifactory.Client)
 	repo := newRemoteRepositoryFromResource(d)
-	c.UpdateRepository(repo.Key, repo)
-
-	wait := repoCreateWait()
-	wait.Refresh = func() (interface{}, string, error) {
-		log.Printf("[DEBUG] Checking if Group %s is created", repo.Key)
-
-		newRepo := artifactory.RemoteRepositoryConfiguration{}
-		err := c.GetRepository(repo.Key, &newRepo)
-		if err != nil {
-			return newRepo, "updating", err
-		}
-		log.Printf("[DEBUG] Group %s is created", repo.Key)
-		return newRepo, "updated", err
+	if err := c.UpdateRepository(repo.Key, repo); err != nil {
+		return err
 	}
 
-	_, err := wait.WaitForState()
-	if err != nil {
+	if err := waitForRepository(c, repo.Key, repo, d.Timeout(schema.TimeoutUpdate)); err != nil {
 		return err
 	}
 	return resourceRemoteRepositoryRead(d, m)
@@ -351,3 +487,20 @@ func resourceRemoteRepositoryDelete(d *schema.ResourceData, m interface{}) error
 func resourceRemoteDescriptionDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
 	return old == fmt.Sprintf("%s (local file cache)", new)
 }
+
+// hashPassword stores a hash of the password in the state, which is enough to
+// notice when it changes
+func hashPassword(v interface{}) string {
+	if v.(string) == "" {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(v.(string))))
+}
+
+// resourceRemotePasswordWriteOnlyDiffSuppress only lets the write-only
+// password through when the repository is created, or when
+// password_wo_version changes. It is never stored, so it always differs from
+// the state otherwise.
+func resourceRemotePasswordWriteOnlyDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	return d.Id() != "" && !d.HasChange("password_wo_version")
+}
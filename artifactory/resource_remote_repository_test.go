@@ -1,9 +1,13 @@
 package artifactory
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
 )
 
 const testAccRemoteRepository_basic = `
@@ -32,8 +36,8 @@ func TestAccRemoteRepository_basic(t *testing.T) {
 const testAccRemoteRepository_full = `
 resource "artifactory_remote_repository" "foobar" {
 	key                                   = "acctest-remote-full"
-	package_type                          = "npm"
-	url                                   = "https://registry.npmjs.org/"
+	package_type                          = "maven"
+	url                                   = "https://repo1.maven.org/maven2/"
 	username                              = "user"
 	password                              = "pass"
     proxy                                 = ""
@@ -41,7 +45,7 @@ resource "artifactory_remote_repository" "foobar" {
 	notes                                 = "notes"
 	includes_pattern                      = "**/*.js"
 	excludes_pattern                      = "**/*.jsx"
-	repo_layout_ref                       = "npm-default"
+	repo_layout_ref                       = "maven-2-default"
 	remote_repo_checksum_policy_type      = ""
 	handle_releases                       = true
 	handle_snapshots                      = true
@@ -81,16 +85,16 @@ func TestAccRemoteRepository_full(t *testing.T) {
 				Config: testAccRemoteRepository_full,
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "key", "acctest-remote-full"),
-					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "package_type", "npm"),
-					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "url", "https://registry.npmjs.org/"),
+					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "package_type", "maven"),
+					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "url", "https://repo1.maven.org/maven2/"),
 					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "username", "user"),
-					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "password", "pass"),
+					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "password", hashPassword("pass")),
 					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "proxy", ""),
 					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "description", "desc (local file cache)"),
 					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "notes", "notes"),
 					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "includes_pattern", "**/*.js"),
 					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "excludes_pattern", "**/*.jsx"),
-					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "repo_layout_ref", "npm-default"),
+					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "repo_layout_ref", "maven-2-default"),
 					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "remote_repo_checksum_policy_type", ""),
 					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "handle_releases", "true"),
 					resource.TestCheckResourceAttr("artifactory_remote_repository.foobar", "handle_snapshots", "true"),
@@ -124,3 +128,253 @@ func TestAccRemoteRepository_full(t *testing.T) {
 		},
 	})
 }
+
+const testAccRemoteRepository_formats = `
+resource "artifactory_remote_repository" "cargo" {
+	key          = "acctest-remote-cargo"
+	package_type = "cargo"
+	url          = "https://index.crates.io/"
+
+	cargo {
+		git_registry_url = "https://github.com/rust-lang/crates.io-index"
+		anonymous_access = true
+	}
+}
+
+resource "artifactory_remote_repository" "cocoapods" {
+	key          = "acctest-remote-cocoapods"
+	package_type = "cocoapods"
+	url          = "https://github.com/"
+
+	cocoapods {
+		specs_repo_url = "https://github.com/CocoaPods/Specs"
+	}
+}
+
+resource "artifactory_remote_repository" "go" {
+	key          = "acctest-remote-go"
+	package_type = "go"
+	url          = "https://proxy.golang.org/"
+
+	go {
+		vcs_download_url = "https://vcs.example.com/download"
+	}
+}
+
+resource "artifactory_remote_repository" "helm" {
+	key          = "acctest-remote-helm"
+	package_type = "helm"
+	url          = "https://charts.helm.sh/stable"
+
+	helm {
+		charts_base_url = "https://charts.example.com"
+	}
+}
+
+resource "artifactory_remote_repository" "terraform" {
+	key          = "acctest-remote-terraform"
+	package_type = "terraform"
+	url          = "https://github.com/"
+
+	terraform {
+		registry_url  = "https://registry.terraform.io"
+		providers_url = "https://releases.hashicorp.com"
+	}
+}`
+
+func TestAccRemoteRepository_formats(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy("artifactory_remote_repository.helm"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccRemoteRepository_formats,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("artifactory_remote_repository.cargo", "cargo.0.git_registry_url", "https://github.com/rust-lang/crates.io-index"),
+					resource.TestCheckResourceAttr("artifactory_remote_repository.cargo", "cargo.0.anonymous_access", "true"),
+					resource.TestCheckResourceAttr("artifactory_remote_repository.cocoapods", "cocoapods.0.specs_repo_url", "https://github.com/CocoaPods/Specs"),
+					resource.TestCheckResourceAttr("artifactory_remote_repository.go", "go.0.vcs_download_url", "https://vcs.example.com/download"),
+					resource.TestCheckResourceAttr("artifactory_remote_repository.helm", "helm.0.charts_base_url", "https://charts.example.com"),
+					resource.TestCheckResourceAttr("artifactory_remote_repository.helm", "terraform.#", "0"),
+					resource.TestCheckResourceAttr("artifactory_remote_repository.terraform", "terraform.0.registry_url", "https://registry.terraform.io"),
+					resource.TestCheckResourceAttr("artifactory_remote_repository.terraform", "terraform.0.providers_url", "https://releases.hashicorp.com"),
+				),
+			},
+		},
+	})
+}
+
+const testAccRemoteRepository_npmWithJars = `
+resource "artifactory_remote_repository" "foobar" {
+	key                = "acctest-remote-npm"
+	package_type       = "npm"
+	url                = "https://registry.npmjs.org/"
+	fetch_jars_eagerly = true
+}`
+
+func TestAccRemoteRepository_packageTypeAttributes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccRemoteRepository_npmWithJars,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("fetch_jars_eagerly cannot be set on npm repositories"),
+			},
+		},
+	})
+}
+
+const testAccRemoteRepository_stringsSet = `
+resource "artifactory_remote_repository" "foobar" {
+	key              = "acctest-remote-strings"
+	url              = "https://repo1.maven.org/maven2/"
+	username         = "someone"
+	proxy            = "corporate"
+	notes            = "some notes"
+	excludes_pattern = "**/*.tmp"
+	local_address    = "10.0.0.1"
+	property_sets    = [ "artifactory" ]
+}`
+
+const testAccRemoteRepository_stringsCleared = `
+resource "artifactory_remote_repository" "foobar" {
+	key = "acctest-remote-strings"
+	url = "https://repo1.maven.org/maven2/"
+}`
+
+func TestAccRemoteRepository_clearSettings(t *testing.T) {
+	resourceName := "artifactory_remote_repository.foobar"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccRemoteRepository_stringsSet,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "username", "someone"),
+					resource.TestCheckResourceAttr(resourceName, "proxy", "corporate"),
+					resource.TestCheckResourceAttr(resourceName, "local_address", "10.0.0.1"),
+					resource.TestCheckResourceAttr(resourceName, "property_sets.#", "1"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccRemoteRepository_stringsCleared,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "username", ""),
+					resource.TestCheckResourceAttr(resourceName, "proxy", ""),
+					resource.TestCheckResourceAttr(resourceName, "notes", ""),
+					resource.TestCheckResourceAttr(resourceName, "excludes_pattern", ""),
+					resource.TestCheckResourceAttr(resourceName, "local_address", ""),
+					resource.TestCheckResourceAttr(resourceName, "property_sets.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+const testAccRemoteRepository_password = `
+resource "artifactory_remote_repository" "foobar" {
+	key      = "acctest-remote-password"
+	url      = "https://repo1.maven.org/maven2/"
+	username = "user"
+	password = "%s"
+}`
+
+func TestAccRemoteRepository_password(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_remote_repository.foobar"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccRemoteRepository_password, "secret1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "password", hashPassword("secret1")),
+					testAccCheckRepositoryPassword("acctest-remote-password", "secret1"),
+					testAccCheckNotInState(resourceName, "secret1"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccRemoteRepository_password, "secret2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "password", hashPassword("secret2")),
+					testAccCheckRepositoryPassword("acctest-remote-password", "secret2"),
+				),
+			},
+		},
+	})
+}
+
+const testAccRemoteRepository_passwordWriteOnly = `
+resource "artifactory_remote_repository" "foobar" {
+	key                 = "acctest-remote-password-wo"
+	url                 = "https://repo1.maven.org/maven2/"
+	username            = "user"
+	password_wo         = "%s"
+	password_wo_version = %d
+}`
+
+func TestAccRemoteRepository_passwordWriteOnly(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_remote_repository.foobar"
+	key := "acctest-remote-password-wo"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccRemoteRepository_passwordWriteOnly, "secret1", 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "password_wo", ""),
+					resource.TestCheckNoResourceAttr(resourceName, "password"),
+					testAccCheckRepositoryPassword(key, "secret1"),
+					testAccCheckNotInState(resourceName, "secret1"),
+				),
+			},
+			resource.TestStep{
+				// without a new version, the change is not noticed
+				Config: fmt.Sprintf(testAccRemoteRepository_passwordWriteOnly, "secret2", 1),
+				Check:  testAccCheckRepositoryPassword(key, "secret1"),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccRemoteRepository_passwordWriteOnly, "secret2", 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "password_wo", ""),
+					testAccCheckRepositoryPassword(key, "secret2"),
+					testAccCheckNotInState(resourceName, "secret2"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckRepositoryPassword checks the password the fake stored for a remote repository
+func testAccCheckRepositoryPassword(key, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if got := testAccFake.repositoryPassword(key); got != expected {
+			return fmt.Errorf("expected password of %s to be %q, got %q", key, expected, got)
+		}
+		return nil
+	}
+}
+
+// testAccCheckNotInState checks that a secret is not stored in any attribute of a resource
+func testAccCheckNotInState(id, secret string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for k, v := range s.RootModule().Resources[id].Primary.Attributes {
+			if strings.Contains(v, secret) {
+				return fmt.Errorf("%s of %s holds the secret", k, id)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,218 @@
+package artifactory
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+const testAccPushReplication_full = `
+resource "artifactory_local_repository" "foobar" {
+	key = "acctest-push-replication"
+}
+
+resource "artifactory_push_replication" "foobar" {
+	repo_key = "${artifactory_local_repository.foobar.key}"
+	cron_exp = "0 0 12 * * ?"
+
+	replications {
+		url          = "https://dr-one.example.com/artifactory/acctest-push-replication"
+		username     = "replicator"
+		password     = "secret"
+		sync_deletes = true
+	}
+
+	replications {
+		url             = "https://dr-two.example.com/artifactory/acctest-push-replication"
+		username        = "replicator"
+		password        = "secret"
+		sync_statistics = true
+		path_prefix     = "com/example"
+	}
+}`
+
+func TestAccPushReplication_full(t *testing.T) {
+	resourceName := "artifactory_push_replication.foobar"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckReplicationDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPushReplication_full,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "repo_key", "acctest-push-replication"),
+					resource.TestCheckResourceAttr(resourceName, "cron_exp", "0 0 12 * * ?"),
+					resource.TestCheckResourceAttr(resourceName, "replications.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "replications.0.sync_deletes", "true"),
+					resource.TestCheckResourceAttr(resourceName, "replications.0.password", hashPassword("secret")),
+					resource.TestCheckResourceAttr(resourceName, "replications.1.sync_statistics", "true"),
+					resource.TestCheckResourceAttr(resourceName, "replications.1.path_prefix", "com/example"),
+				),
+			},
+		},
+	})
+}
+
+const testAccPushReplication_password = `
+resource "artifactory_local_repository" "foobar" {
+	key = "acctest-push-replication-password"
+}
+
+resource "artifactory_push_replication" "foobar" {
+	repo_key = "${artifactory_local_repository.foobar.key}"
+	cron_exp = "%s"
+
+	replications {
+		url      = "https://dr-one.example.com/artifactory/acctest-push-replication"
+		username = "replicator"
+		password = "%s"
+	}
+
+	replications {
+		url                 = "https://dr-two.example.com/artifactory/acctest-push-replication"
+		username            = "replicator"
+		password_wo         = "%s"
+		password_wo_version = %d
+	}
+}`
+
+func TestAccPushReplication_password(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_push_replication.foobar"
+	key := "acctest-push-replication-password"
+	one := "https://dr-one.example.com/artifactory/acctest-push-replication"
+	two := "https://dr-two.example.com/artifactory/acctest-push-replication"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckReplicationDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccPushReplication_password, "0 0 12 * * ?", "secret1", "secret2", 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "replications.0.password", hashPassword("secret1")),
+					resource.TestCheckResourceAttr(resourceName, "replications.1.password_wo", ""),
+					testAccCheckReplicationPassword(key, one, "secret1"),
+					testAccCheckReplicationPassword(key, two, "secret2"),
+					testAccCheckNotInState(resourceName, "secret1"),
+					testAccCheckNotInState(resourceName, "secret2"),
+				),
+			},
+			resource.TestStep{
+				// the passwords are not sent again, and a new write-only one is
+				// only noticed with a new version
+				Config: fmt.Sprintf(testAccPushReplication_password, "0 0 6 * * ?", "secret1", "secret3", 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "cron_exp", "0 0 6 * * ?"),
+					testAccCheckReplicationPassword(key, one, "secret1"),
+					testAccCheckReplicationPassword(key, two, "secret2"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccPushReplication_password, "0 0 6 * * ?", "secret4", "secret3", 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "replications.0.password", hashPassword("secret4")),
+					testAccCheckReplicationPassword(key, one, "secret4"),
+					testAccCheckReplicationPassword(key, two, "secret3"),
+					testAccCheckNotInState(resourceName, "secret4"),
+					testAccCheckNotInState(resourceName, "secret3"),
+				),
+			},
+		},
+	})
+}
+
+const testAccPushReplication_bothPasswords = `
+resource "artifactory_push_replication" "foobar" {
+	repo_key = "acctest-push-replication-both"
+	cron_exp = "0 0 12 * * ?"
+
+	replications {
+		url         = "https://dr-one.example.com/artifactory/acctest-push-replication"
+		password    = "secret1"
+		password_wo = "secret2"
+	}
+}`
+
+func TestAccPushReplication_bothPasswords(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccPushReplication_bothPasswords,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("replications.0: password conflicts with password_wo"),
+			},
+		},
+	})
+}
+
+// testAccCheckReplicationPassword checks the password the fake stored for a push replication target
+func testAccCheckReplicationPassword(key, url, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if got := testAccFake.replicationPassword(key, url); got != expected {
+			return fmt.Errorf("expected password of %s to be %q, got %q", url, expected, got)
+		}
+		return nil
+	}
+}
+
+const testAccPullReplication_full = `
+resource "artifactory_remote_repository" "foobar" {
+	key          = "acctest-pull-replication"
+	package_type = "npm"
+	url          = "https://registry.npmjs.org/"
+}
+
+resource "artifactory_pull_replication" "foobar" {
+	repo_key        = "${artifactory_remote_repository.foobar.key}"
+	cron_exp        = "0 0 12 * * ?"
+	sync_deletes    = true
+	sync_properties = false
+}`
+
+func TestAccPullReplication_full(t *testing.T) {
+	resourceName := "artifactory_pull_replication.foobar"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckReplicationDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPullReplication_full,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "repo_key", "acctest-pull-replication"),
+					resource.TestCheckResourceAttr(resourceName, "cron_exp", "0 0 12 * * ?"),
+					resource.TestCheckResourceAttr(resourceName, "enabled", "true"),
+					resource.TestCheckResourceAttr(resourceName, "sync_deletes", "true"),
+					resource.TestCheckResourceAttr(resourceName, "sync_properties", "false"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckReplicationDestroy(id string) func(*terraform.State) error {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(artifactory.Client)
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found %s", id)
+		}
+
+		replications, err := client.GetReplications(rs.Primary.ID)
+
+		if err == nil && len(replications) > 0 {
+			return fmt.Errorf("Replication of %s still exists", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,124 @@
+package artifactory
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+func resourceRepoLayout() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceRepoLayoutCreate,
+		Read:   resourceRepoLayoutRead,
+		Update: resourceRepoLayoutUpdate,
+		Delete: resourceRepoLayoutDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"artifact_path_pattern": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"distinctive_descriptor_path_pattern": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"descriptor_path_pattern": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"folder_integration_revision_regexp": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"file_integration_revision_regexp": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+func newRepoLayoutFromResource(d *schema.ResourceData) *artifactory.RepoLayout {
+	return &artifactory.RepoLayout{
+		Name:                             d.Get("name").(string),
+		ArtifactPathPattern:              d.Get("artifact_path_pattern").(string),
+		DistinctiveDescriptorPathPattern: d.Get("distinctive_descriptor_path_pattern").(bool),
+		DescriptorPathPattern:            d.Get("descriptor_path_pattern").(string),
+		FolderIntegrationRevisionRegExp:  d.Get("folder_integration_revision_regexp").(string),
+		FileIntegrationRevisionRegExp:    d.Get("file_integration_revision_regexp").(string),
+	}
+}
+
+func resourceRepoLayoutRead(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+
+	layout, err := c.GetRepoLayout(d.Id())
+
+	if artifactory.IsNotFound(err) {
+		log.Printf("[WARN] Repo layout %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	d.Set("name", layout.Name)
+	d.Set("artifact_path_pattern", layout.ArtifactPathPattern)
+	d.Set("distinctive_descriptor_path_pattern", layout.DistinctiveDescriptorPathPattern)
+	d.Set("descriptor_path_pattern", layout.DescriptorPathPattern)
+	d.Set("folder_integration_revision_regexp", layout.FolderIntegrationRevisionRegExp)
+	d.Set("file_integration_revision_regexp", layout.FileIntegrationRevisionRegExp)
+
+	return nil
+}
+
+func resourceRepoLayoutCreate(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	layout := newRepoLayoutFromResource(d)
+
+	// saving a layout replaces the one with the same name, which may be in use
+	_, err := c.GetRepoLayout(layout.Name)
+	if err == nil {
+		return fmt.Errorf("repo layout %s already exists, import it to manage it", layout.Name)
+	}
+	if !artifactory.IsNotFound(err) {
+		return err
+	}
+
+	err = c.SaveRepoLayout(layout)
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(layout.Name)
+	return resourceRepoLayoutRead(d, m)
+}
+
+func resourceRepoLayoutUpdate(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	err := c.SaveRepoLayout(newRepoLayoutFromResource(d))
+
+	if err != nil {
+		return err
+	}
+
+	return resourceRepoLayoutRead(d, m)
+}
+
+func resourceRepoLayoutDelete(d *schema.ResourceData, m interface{}) error {
+	c := m.(artifactory.Client)
+	return c.DeleteRepoLayout(d.Id())
+}
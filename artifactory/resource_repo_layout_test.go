@@ -0,0 +1,128 @@
+package artifactory
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+func testAccRepoLayout_full(descriptor string) string {
+	return fmt.Sprintf(`
+resource "artifactory_repo_layout" "foobar" {
+	name                                = "acctest-league-layout"
+	artifact_path_pattern               = "[org]/[module]/[baseRev](-[folderItegRev])/[module]-[baseRev](-[fileItegRev])(-[classifier]).[ext]"
+	distinctive_descriptor_path_pattern = true
+	descriptor_path_pattern             = "[org]/[module]/[baseRev](-[folderItegRev])/%s"
+	folder_integration_revision_regexp  = "SNAPSHOT"
+	file_integration_revision_regexp    = "SNAPSHOT|(?:(?:[0-9]{8}.[0-9]{6})-(?:[0-9]+))"
+}
+
+resource "artifactory_local_repository" "foobar" {
+	key             = "acctest-league-local"
+	package_type    = "generic"
+	repo_layout_ref = "${artifactory_repo_layout.foobar.name}"
+}`, descriptor)
+}
+
+func TestAccRepoLayout_full(t *testing.T) {
+	resourceName := "artifactory_repo_layout.foobar"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepoLayoutDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccRepoLayout_full("[module]-[baseRev].pom"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", "acctest-league-layout"),
+					resource.TestCheckResourceAttr(resourceName, "distinctive_descriptor_path_pattern", "true"),
+					resource.TestCheckResourceAttr(resourceName, "descriptor_path_pattern", "[org]/[module]/[baseRev](-[folderItegRev])/[module]-[baseRev].pom"),
+					resource.TestCheckResourceAttr(resourceName, "folder_integration_revision_regexp", "SNAPSHOT"),
+					resource.TestCheckResourceAttr("artifactory_local_repository.foobar", "repo_layout_ref", "acctest-league-layout"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccRepoLayout_full("[module]-[baseRev].ivy"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "descriptor_path_pattern", "[org]/[module]/[baseRev](-[folderItegRev])/[module]-[baseRev].ivy"),
+				),
+			},
+			resource.TestStep{
+				PreConfig: func() {
+					client := testAccProvider.Meta().(artifactory.Client)
+					layout, _ := client.GetRepoLayout("acctest-league-layout")
+					layout.FolderIntegrationRevisionRegExp = "RELEASE"
+					client.SaveRepoLayout(layout)
+				},
+				Config:             testAccRepoLayout_full("[module]-[baseRev].ivy"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			resource.TestStep{
+				Config: testAccRepoLayout_full("[module]-[baseRev].ivy"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "folder_integration_revision_regexp", "SNAPSHOT"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+const testAccRepoLayout_existing = `
+resource "artifactory_repo_layout" "foobar" {
+	name                  = "maven-2-default"
+	artifact_path_pattern = "[org]/[module]/[baseRev]/[module]-[baseRev].[ext]"
+}`
+
+func TestAccRepoLayout_existing(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		CheckDestroy: func(s *terraform.State) error {
+			// the built-in layout is left as it was
+			client := testAccProvider.Meta().(artifactory.Client)
+			layout, err := client.GetRepoLayout("maven-2-default")
+			if err != nil {
+				return err
+			}
+			if layout.ArtifactPathPattern == "[org]/[module]/[baseRev]/[module]-[baseRev].[ext]" {
+				return fmt.Errorf("Repo layout maven-2-default was overwritten")
+			}
+			return nil
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccRepoLayout_existing,
+				ExpectError: regexp.MustCompile("repo layout maven-2-default already exists, import it to manage it"),
+			},
+		},
+	})
+}
+
+func testAccCheckRepoLayoutDestroy(id string) func(*terraform.State) error {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(artifactory.Client)
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found %s", id)
+		}
+
+		_, err := client.GetRepoLayout(rs.Primary.ID)
+
+		if err == nil {
+			return fmt.Errorf("Repo layout %s still exists", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
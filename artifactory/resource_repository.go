@@ -1,6 +1,18 @@
 package artifactory
 
-import "strings"
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
 
 var types,
 	packageTypes,
@@ -13,7 +25,8 @@ var types,
 
 func init() {
 	types = []string{"local", "remote", "virtual"}
-	packageTypes = strings.Split("maven|gradle|ivy|sbt|nuget|gems|npm|bower|debian|composer|pypi|docker|vagrant|gitlfs|conan|generic|rpm", "|")
+	packageTypes = strings.Split("maven|gradle|ivy|sbt|nuget|gems|npm|bower|debian|composer|pypi|docker|vagrant|gitlfs|conan|generic|rpm|"+
+		"go|helm|cargo|conda|cran|chef|puppet|alpine|opkg|p2|cocoapods|swift|terraform|huggingface", "|")
 	checksumPolicyTypes = []string{"client-checksums", "server-generated-checksums"}
 	snapshotVersionBehaviors = []string{"unique", "non-unique", "deployer"}
 	remoteRepoChecksumPolicyTypes = []string{"", "generate-if-absent", "fail", "ignore-and-generate", "pass-thru"}
@@ -21,3 +34,332 @@ func init() {
 	vcsGitProviders = []string{"", "github", "bitbucket", "stash", "artifactory", "custom"}
 	pomRepositoryReferencesCleanupPolicy = []string{"discard_active_reference", "discard_any_reference", "nothing"}
 }
+
+// mavenPackageTypes are the package types that use the Maven settings of a repository
+var mavenPackageTypes = []string{"maven", "gradle", "ivy", "sbt"}
+
+// packageTypeAttributes maps the attributes of a repository that only apply to
+// some package types to these types. Artifactory ignores them for the other
+// types, which would show as a perpetual diff.
+type packageTypeAttributes map[string][]string
+
+// appliesTo returns whether attribute key applies to repositories of packageType
+func (a packageTypeAttributes) appliesTo(key, packageType string) bool {
+	types, ok := a[key]
+	if !ok {
+		return true
+	}
+	for _, t := range types {
+		if strings.EqualFold(t, packageType) {
+			return true
+		}
+	}
+	return false
+}
+
+// customizeDiff returns a CustomizeDiff rejecting, at plan time, the
+// attributes that do not apply to the package type of a repository
+func (a packageTypeAttributes) customizeDiff(resource func() *schema.Resource) schema.CustomizeDiffFunc {
+	return func(d *schema.ResourceDiff, m interface{}) error {
+		return a.validate(d, resource().Schema)
+	}
+}
+
+// validate returns an error listing the attributes that are set to something
+// else than their default although they do not apply to the package type of
+// the repository
+func (a packageTypeAttributes) validate(d *schema.ResourceDiff, s map[string]*schema.Schema) error {
+	packageType := d.Get("package_type").(string)
+	if packageType == "" {
+		// not known yet, the check runs again when the repository is applied
+		return nil
+	}
+
+	invalid := make([]string, 0)
+	for key := range a {
+		if !a.appliesTo(key, packageType) && !isDefaultValue(s[key], d.Get(key)) {
+			invalid = append(invalid, key)
+		}
+	}
+
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return fmt.Errorf("%s cannot be set on %s repositories", strings.Join(invalid, ", "), packageType)
+	}
+	return nil
+}
+
+// reset sets the attributes that do not apply to packageType to their
+// default, whatever Artifactory returned for them
+func (a packageTypeAttributes) reset(d *schema.ResourceData, s map[string]*schema.Schema, packageType string) error {
+	for key := range a {
+		if a.appliesTo(key, packageType) {
+			continue
+		}
+
+		var v interface{}
+		switch {
+		case s[key].Default != nil:
+			v = s[key].Default
+		case s[key].Type == schema.TypeBool:
+			v = false
+		case s[key].Type == schema.TypeInt:
+			v = 0
+		case s[key].Type == schema.TypeString:
+			v = ""
+		default:
+			v = []interface{}{}
+		}
+
+		if err := d.Set(key, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isDefaultValue returns whether v is the default value of the attribute
+// described by s, or its zero value when it has no default
+func isDefaultValue(s *schema.Schema, v interface{}) bool {
+	if s.Default != nil {
+		return reflect.DeepEqual(s.Default, v)
+	}
+
+	switch v := v.(type) {
+	case []interface{}:
+		return len(v) == 0
+	case *schema.Set:
+		return v.Len() == 0
+	default:
+		return v == reflect.Zero(reflect.TypeOf(v)).Interface()
+	}
+}
+
+// getBoolRef returns the value of a boolean attribute, or nil when it is not
+// configured so that Artifactory keeps its own default. Unlike the zero value,
+// an explicit false is sent to the server.
+func getBoolRef(d *schema.ResourceData, key string) *bool {
+	if v, ok := d.GetOkExists(key); ok {
+		return artifactory.Bool(v.(bool))
+	}
+	return nil
+}
+
+// getIntRef returns the value of an integer attribute, or nil when it is not
+// configured so that Artifactory keeps its own default
+func getIntRef(d *schema.ResourceData, key string) *int {
+	if v, ok := d.GetOkExists(key); ok {
+		return artifactory.Int(v.(int))
+	}
+	return nil
+}
+
+// repositoryFormatSchema returns an optional block holding the attributes that
+// only apply to repositories of one package type
+func repositoryFormatSchema(attrs map[string]*schema.Schema) *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem:     &schema.Resource{Schema: attrs},
+	}
+}
+
+// getFormatBlock returns the attributes of a package type block, or nil when
+// the block is not configured. A block removed from the configuration returns
+// the zero value of its attributes, so that they are unset in Artifactory.
+func getFormatBlock(d *schema.ResourceData, key string) map[string]interface{} {
+	if l := d.Get(key).([]interface{}); len(l) > 0 && l[0] != nil {
+		return l[0].(map[string]interface{})
+	}
+
+	o, _ := d.GetChange(key)
+	old := o.([]interface{})
+	if len(old) == 0 || old[0] == nil {
+		return nil
+	}
+
+	attrs := make(map[string]interface{})
+	for k, v := range old[0].(map[string]interface{}) {
+		attrs[k] = reflect.Zero(reflect.TypeOf(v)).Interface()
+	}
+	return attrs
+}
+
+// setFormatBlock stores the attributes of a package type block read from
+// Artifactory. The block is left out when none of them is set, unless it is
+// already configured.
+func setFormatBlock(d *schema.ResourceData, key string, attrs map[string]interface{}) error {
+	if len(d.Get(key).([]interface{})) == 0 && isZeroFormatBlock(attrs) {
+		return d.Set(key, []interface{}{})
+	}
+	return d.Set(key, []interface{}{attrs})
+}
+
+func isZeroFormatBlock(attrs map[string]interface{}) bool {
+	for _, v := range attrs {
+		switch v := v.(type) {
+		case []string:
+			if len(v) > 0 {
+				return false
+			}
+		default:
+			if v != reflect.Zero(reflect.TypeOf(v)).Interface() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// repositoryStableReads is the number of identical reads after which a
+// repository that still differs from what was sent is considered converged
+// to another value, such as one normalized by Artifactory
+const repositoryStableReads = 3
+
+// waitForRepository polls Artifactory until the configuration of repository
+// key reflects everything that was sent in repo, or stops changing. Differing
+// attributes are then left to the next read to pick up.
+func waitForRepository(c artifactory.Client, key string, repo interface{}, timeout time.Duration) error {
+	sent, err := repositoryAttributes(repo)
+	if err != nil {
+		return err
+	}
+
+	var diff []string
+	var last map[string]interface{}
+	stable := 0
+	wait := resource.StateChangeConf{
+		Pending:    []string{"updating"},
+		Target:     []string{"updated"},
+		Timeout:    timeout,
+		MinTimeout: 500 * time.Millisecond,
+		Refresh: func() (interface{}, string, error) {
+			log.Printf("[DEBUG] Checking if repository %s is updated", key)
+
+			got := make(map[string]interface{})
+			err := c.GetRepository(key, &got)
+			if artifactory.IsNotFound(err) {
+				diff, last, stable = []string{"key"}, nil, 0
+				return got, "updating", nil
+			}
+			if err != nil {
+				return nil, "", err
+			}
+
+			diff = repositoryDiff(sent, got)
+			if len(diff) > 0 {
+				log.Printf("[DEBUG] Repository %s differs in %s", key, strings.Join(diff, ", "))
+
+				if !reflect.DeepEqual(got, last) {
+					last, stable = got, 0
+				}
+				if stable++; stable >= repositoryStableReads {
+					log.Printf("[WARN] Repository %s did not change in %d reads, keeping the differing %s", key, stable, strings.Join(diff, ", "))
+					return got, "updated", nil
+				}
+				return got, "updating", nil
+			}
+
+			log.Printf("[DEBUG] Repository %s is updated", key)
+			return got, "updated", nil
+		},
+	}
+
+	if _, err := wait.WaitForState(); err != nil {
+		if len(diff) > 0 {
+			return fmt.Errorf("Repository %s did not converge, differing attributes: %s: %s", key, strings.Join(diff, ", "), err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// repositoryAttributes returns the attributes of a repository configuration as
+// they are sent to Artifactory
+func repositoryAttributes(repo interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string]interface{})
+	if err := json.Unmarshal(b, &attrs); err != nil {
+		return nil, err
+	}
+
+	return attrs, nil
+}
+
+// repositoryDiff returns the sorted names of the sent attributes that differ
+// from the ones read back from Artifactory
+func repositoryDiff(sent, got map[string]interface{}) []string {
+	diff := make([]string, 0)
+	for k, v := range sent {
+		if _, ok := got[k]; !ok {
+			// dropped as it does not apply to the package type
+			continue
+		}
+
+		switch k {
+		case "password":
+			// only ever returned in encrypted form
+			continue
+		case "propertySets":
+			// a set, returned in any order
+			if sameStrings(v, got[k]) {
+				continue
+			}
+		case "url":
+			// Artifactory may add or remove the trailing slash
+			if s, ok := v.(string); ok && strings.TrimSuffix(s, "/") == strings.TrimSuffix(fmt.Sprint(got[k]), "/") {
+				continue
+			}
+		case "description":
+			// Artifactory marks the description of remote repositories
+			if desc, ok := got[k].(string); ok && desc == fmt.Sprintf("%s (local file cache)", v) {
+				continue
+			}
+		}
+
+		// enumerated values such as the package type are not case sensitive
+		if s, ok := v.(string); ok {
+			if g, ok := got[k].(string); ok && strings.EqualFold(s, g) {
+				continue
+			}
+		}
+
+		if !reflect.DeepEqual(v, got[k]) {
+			diff = append(diff, k)
+		}
+	}
+
+	sort.Strings(diff)
+	return diff
+}
+
+// sameStrings returns whether two lists decoded from JSON hold the same
+// strings, in any order
+func sameStrings(a, b interface{}) bool {
+	la, ok := a.([]interface{})
+	if !ok {
+		return false
+	}
+	lb, ok := b.([]interface{})
+	if !ok || len(la) != len(lb) {
+		return false
+	}
+
+	sa := make([]string, 0, len(la))
+	for _, v := range la {
+		sa = append(sa, fmt.Sprint(v))
+	}
+	sb := make([]string, 0, len(lb))
+	for _, v := range lb {
+		sb = append(sb, fmt.Sprint(v))
+	}
+	sort.Strings(sa)
+	sort.Strings(sb)
+	return reflect.DeepEqual(sa, sb)
+}
@@ -0,0 +1,98 @@
+package artifactory
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/webdevwilson/go-artifactory/artifactory"
+)
+
+func TestRepositoryDiff(t *testing.T) {
+	cases := []struct {
+		name string
+		sent map[string]interface{}
+		got  map[string]interface{}
+		diff []string
+	}{
+		{
+			name: "same",
+			sent: map[string]interface{}{"key": "libs", "packageType": "maven"},
+			got:  map[string]interface{}{"key": "libs", "packageType": "Maven", "rclass": "local"},
+			diff: []string{},
+		},
+		{
+			name: "property sets in another order",
+			sent: map[string]interface{}{"propertySets": []interface{}{"b", "a"}},
+			got:  map[string]interface{}{"propertySets": []interface{}{"a", "b"}},
+			diff: []string{},
+		},
+		{
+			name: "members in another order",
+			sent: map[string]interface{}{"repositories": []interface{}{"b", "a"}},
+			got:  map[string]interface{}{"repositories": []interface{}{"a", "b"}},
+			diff: []string{"repositories"},
+		},
+		{
+			name: "url with a trailing slash",
+			sent: map[string]interface{}{"url": "https://repo1.maven.org/maven2"},
+			got:  map[string]interface{}{"url": "https://repo1.maven.org/maven2/"},
+			diff: []string{},
+		},
+		{
+			name: "different",
+			sent: map[string]interface{}{"description": "new", "notes": "new", "propertySets": []interface{}{"a"}},
+			got:  map[string]interface{}{"description": "old", "notes": "new", "propertySets": []interface{}{"a", "b"}},
+			diff: []string{"description", "propertySets"},
+		},
+	}
+
+	for _, tc := range cases {
+		if diff := repositoryDiff(tc.sent, tc.got); !reflect.DeepEqual(diff, tc.diff) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.diff, diff)
+		}
+	}
+}
+
+func TestWaitForRepository_stable(t *testing.T) {
+	testAccFakeOnly(t)
+	c := artifactory.NewClient(testAccFake.username, testAccFake.password, testAccFake.URL, http.DefaultClient)
+
+	repo := &artifactory.LocalRepositoryConfiguration{
+		Key:         "acctest-wait",
+		RClass:      "local",
+		PackageType: "generic",
+		Description: "normalized",
+	}
+	if err := c.CreateRepository(repo.Key, repo); err != nil {
+		t.Fatal(err)
+	}
+	defer c.DeleteRepository(repo.Key)
+
+	// a value that never shows up is accepted long before the timeout
+	repo.Description = "sent"
+	start := time.Now()
+	if err := waitForRepository(c, repo.Key, repo, 5*time.Minute); err != nil {
+		t.Errorf("expected the stable repository to be accepted, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Second {
+		t.Errorf("expected to stop polling once the repository stopped changing, waited %s", elapsed)
+	}
+}
+
+func TestWaitForRepository_timeout(t *testing.T) {
+	testAccFakeOnly(t)
+	c := artifactory.NewClient(testAccFake.username, testAccFake.password, testAccFake.URL, http.DefaultClient)
+
+	repo := &artifactory.LocalRepositoryConfiguration{Key: "acctest-wait-missing", RClass: "local"}
+	start := time.Now()
+	err := waitForRepository(c, repo.Key, repo, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "differing attributes: key") {
+		t.Errorf("expected an error about the missing repository, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Second {
+		t.Errorf("expected to stop polling after the timeout, waited %s", elapsed)
+	}
+}
@@ -1,13 +1,22 @@
 package artifactory
 
 import (
-	"math/rand"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"math/big"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/webdevwilson/go-artifactory/artifactory"
 )
 
-const randomPasswordLength = 16
+const (
+	passwordLower   = "abcdefghijklmnopqrstuvwxyz"
+	passwordUpper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	passwordNumeric = "0123456789"
+	passwordSpecial = "!#$%&*()-_=+[]{}<>:?"
+)
 
 func resourceUser() *schema.Resource {
 	return &schema.Resource{
@@ -16,7 +25,7 @@ func resourceUser() *schema.Resource {
 		Update: resourceUserUpdate,
 		Delete: resourceUserDelete,
 		Importer: &schema.ResourceImporter{
-			State: virtualRepositoryImportStatePassthrough,
+			State: resourceUserImport,
 		},
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -44,14 +53,109 @@ func resourceUser() *schema.Resource {
 				Set:      schema.HashString,
 				Optional: true,
 			},
+			"manage_default_groups": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"realm": &schema.Schema{
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"internal_password_disabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"last_logged_in": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"password": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"password_policy": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"length": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      16,
+							ValidateFunc: validation.IntBetween(8, 128),
+						},
+						"min_lower": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"min_upper": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"min_numeric": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"min_special": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"special_chars": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  passwordSpecial,
+						},
+					},
+				},
+			},
 		},
 	}
 }
 
+// passwordPolicy describes the passwords generated for users without a configured password
+type passwordPolicy struct {
+	length       int
+	minLower     int
+	minUpper     int
+	minNumeric   int
+	minSpecial   int
+	specialChars string
+}
+
+func newPasswordPolicyFromResource(d *schema.ResourceData) passwordPolicy {
+	policy := passwordPolicy{
+		length:       16,
+		minLower:     1,
+		minUpper:     1,
+		minNumeric:   1,
+		minSpecial:   1,
+		specialChars: passwordSpecial,
+	}
+
+	if l := d.Get("password_policy").([]interface{}); len(l) > 0 && l[0] != nil {
+		p := l[0].(map[string]interface{})
+		policy.length = p["length"].(int)
+		policy.minLower = p["min_lower"].(int)
+		policy.minUpper = p["min_upper"].(int)
+		policy.minNumeric = p["min_numeric"].(int)
+		policy.minSpecial = p["min_special"].(int)
+		policy.specialChars = p["special_chars"].(string)
+	}
+
+	return policy
+}
+
 func newUserFromResource(d *schema.ResourceData) *artifactory.User {
 	// Artifactory defaults to admin, let's not do that
 	user := &artifactory.User{}
@@ -76,9 +180,6 @@ func newUserFromResource(d *schema.ResourceData) *artifactory.User {
 		user.Realm = v.(string)
 	}
 
-	// create a random password
-	user.Password = generatePassword()
-
 	if v, ok := d.GetOk("groups"); ok {
 		l := v.(*schema.Set).List()
 		groups := make([]string, 0, len(l))
@@ -96,10 +197,22 @@ func newUserFromResource(d *schema.ResourceData) *artifactory.User {
 	return user
 }
 
+func resourceUserImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	// defaults of arguments that are not read from Artifactory
+	d.Set("manage_default_groups", false)
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceUserRead(d *schema.ResourceData, m interface{}) error {
 	c := m.(artifactory.Client)
 
-	user, err := c.GetUser(d.Get("name").(string))
+	user, err := c.GetUser(d.Id())
+
+	if artifactory.IsNotFound(err) {
+		log.Printf("[WARN] User %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
 
 	if err != nil {
 		return err
@@ -110,13 +223,61 @@ func resourceUserRead(d *schema.ResourceData, m interface{}) error {
 	d.Set("is_admin", user.Admin)
 	d.Set("is_editable", user.ProfileUpdatable)
 	d.Set("realm", user.Realm)
+	d.Set("internal_password_disabled", user.InternalPasswordDisabled)
+	d.Set("last_logged_in", user.LastLoggedIn)
+
+	groups := user.Groups
+	if !d.Get("manage_default_groups").(bool) {
+		// hide the groups the user joined automatically, unless configured
+		configured := d.Get("groups").(*schema.Set)
+		defaults, err := defaultGroups(c, user.Groups)
+		if err != nil {
+			return err
+		}
+
+		groups = make([]string, 0, len(user.Groups))
+		for _, g := range user.Groups {
+			if configured.Contains(g) || !defaults[g] {
+				groups = append(groups, g)
+			}
+		}
+	}
+	d.Set("groups", groups)
 
 	return nil
 }
 
+// defaultGroups returns which of the groups are joined automatically by new users
+func defaultGroups(c artifactory.Client, groups []string) (map[string]bool, error) {
+	defaults := make(map[string]bool)
+	for _, name := range groups {
+		group, err := c.GetGroup(name)
+		if artifactory.IsNotFound(err) {
+			// groups of external realms do not have to exist in Artifactory
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defaults[name] = group.AutoJoin
+	}
+	return defaults, nil
+}
+
 func resourceUserCreate(d *schema.ResourceData, m interface{}) error {
 	user := newUserFromResource(d)
 	c := m.(artifactory.Client)
+
+	user.Password = d.Get("password").(string)
+	if user.Password == "" {
+		// the user is expected to reset the unknown password
+		password, err := generatePassword(newPasswordPolicyFromResource(d))
+		if err != nil {
+			return err
+		}
+		user.Password = password
+	}
+
 	err := c.CreateUser(user)
 
 	if err != nil {
@@ -130,16 +291,64 @@ func resourceUserCreate(d *schema.ResourceData, m interface{}) error {
 func resourceUserUpdate(d *schema.ResourceData, m interface{}) error {
 	c := m.(artifactory.Client)
 	user := newUserFromResource(d)
+
+	// the password is left alone unless the password argument changes. When it
+	// is removed, the known password is replaced by a random, expired one.
+	rotate := false
+	if d.HasChange("password") {
+		user.Password = d.Get("password").(string)
+		if user.Password == "" {
+			password, err := generatePassword(newPasswordPolicyFromResource(d))
+			if err != nil {
+				return err
+			}
+			user.Password = password
+			rotate = true
+		}
+	}
+
+	// the groups are only sent when they change, so that the groups the user
+	// is added to by artifactory_group_membership are kept. An empty list
+	// removes the user from all groups, while null keeps them.
+	switch {
+	case !d.HasChange("groups"):
+		user.Groups = nil
+	case user.Groups == nil:
+		user.Groups = []string{}
+	}
+
+	userMutexKV.Lock(user.Name)
+	defer userMutexKV.Unlock(user.Name)
+
+	if user.Groups != nil && !d.Get("manage_default_groups").(bool) {
+		// keep the groups the user joined automatically, as they are not configured
+		current, err := c.GetUser(user.Name)
+		if err != nil {
+			return err
+		}
+		defaults, err := defaultGroups(c, current.Groups)
+		if err != nil {
+			return err
+		}
+		for _, g := range current.Groups {
+			if defaults[g] && !d.Get("groups").(*schema.Set).Contains(g) {
+				user.Groups = append(user.Groups, g)
+			}
+		}
+	}
+
 	err := c.UpdateUser(user)
 
 	if err != nil {
 		return err
 	}
 
-	err = c.ExpireUserPassword(user.Name)
+	if rotate {
+		err = c.ExpireUserPassword(user.Name)
 
-	if err != nil {
-		return err
+		if err != nil {
+			return err
+		}
 	}
 
 	return resourceUserRead(d, m)
@@ -151,12 +360,74 @@ func resourceUserDelete(d *schema.ResourceData, m interface{}) error {
 	return c.DeleteUser(user.Name)
 }
 
-// generatePassword used as default func to generate user passwords
-func generatePassword() string {
-	letters := []byte("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
-	b := make([]byte, randomPasswordLength)
-	for i := range b {
-		b[i] = letters[rand.Int63()%int64(len(letters))]
+// generatePassword returns a random password satisfying policy
+func generatePassword(policy passwordPolicy) (string, error) {
+	classes := []struct {
+		chars string
+		min   int
+	}{
+		{passwordLower, policy.minLower},
+		{passwordUpper, policy.minUpper},
+		{passwordNumeric, policy.minNumeric},
+		{policy.specialChars, policy.minSpecial},
+	}
+
+	required := 0
+	all := ""
+	for _, class := range classes {
+		if class.min > 0 && class.chars == "" {
+			return "", fmt.Errorf("password_policy requires special characters, but special_chars is empty")
+		}
+		required += class.min
+		all += class.chars
+	}
+	if required > policy.length {
+		return "", fmt.Errorf("password_policy requires %d characters, more than its length of %d", required, policy.length)
+	}
+
+	b := make([]byte, 0, policy.length)
+	for _, class := range classes {
+		for i := 0; i < class.min; i++ {
+			ch, err := randomChar(class.chars)
+			if err != nil {
+				return "", err
+			}
+			b = append(b, ch)
+		}
+	}
+	for len(b) < policy.length {
+		ch, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		b = append(b, ch)
+	}
+
+	// shuffle so the required characters are not at predictable positions
+	for i := len(b) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		b[i], b[j] = b[j], b[i]
+	}
+
+	return string(b), nil
+}
+
+func randomChar(chars string) (byte, error) {
+	i, err := randomInt(len(chars))
+	if err != nil {
+		return 0, err
+	}
+	return chars[i], nil
+}
+
+// randomInt returns a uniformly distributed random number in [0, n)
+func randomInt(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
 	}
-	return string(b)
+	return int(i.Int64()), nil
 }
@@ -2,6 +2,8 @@ package artifactory
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -80,3 +82,271 @@ func testAccCheckUserDestroy(id string) func(*terraform.State) error {
 		return nil
 	}
 }
+
+const testAccUser_generatedPassword = `
+resource "artifactory_user" "foobar" {
+	name  = "donny"
+	email = "donny@domain.com"
+
+	password_policy {
+		length      = 24
+		min_numeric = 3
+		min_special = 3
+	}
+}`
+
+const testAccUser_emailChanged = `
+resource "artifactory_user" "foobar" {
+	name  = "donny"
+	email = "theodore.donald.kerabatsos@domain.com"
+
+	password_policy {
+		length      = 24
+		min_numeric = 3
+		min_special = 3
+	}
+}`
+
+const testAccUser_knownPassword = `
+resource "artifactory_user" "foobar" {
+	name     = "donny"
+	email    = "theodore.donald.kerabatsos@domain.com"
+	password = "Sh4mus-Th3-B0wler"
+}`
+
+func TestAccUser_password(t *testing.T) {
+	testAccFakeOnly(t)
+	var generated string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckUserDestroy("artifactory_user.foobar"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccUser_generatedPassword,
+				Check: testAccCheckUserPassword("donny", func(password string, expired bool) error {
+					generated = password
+					if len(password) != 24 || strings.IndexAny(password, passwordNumeric) < 0 {
+						return fmt.Errorf("expected a password following the policy, got %q", password)
+					}
+					if expired {
+						return fmt.Errorf("expected the generated password not to be expired")
+					}
+					return nil
+				}),
+			},
+			resource.TestStep{
+				Config: testAccUser_emailChanged,
+				Check: testAccCheckUserPassword("donny", func(password string, expired bool) error {
+					if password != generated || expired {
+						return fmt.Errorf("expected the password to be left alone when the email changes")
+					}
+					return nil
+				}),
+			},
+			resource.TestStep{
+				Config: testAccUser_knownPassword,
+				Check: testAccCheckUserPassword("donny", func(password string, expired bool) error {
+					if password != "Sh4mus-Th3-B0wler" || expired {
+						return fmt.Errorf("expected the configured password to be set, got %q (expired %t)", password, expired)
+					}
+					return nil
+				}),
+			},
+			resource.TestStep{
+				Config: testAccUser_emailChanged,
+				Check: testAccCheckUserPassword("donny", func(password string, expired bool) error {
+					if password == "Sh4mus-Th3-B0wler" || !expired {
+						return fmt.Errorf("expected the removed password to be rotated and expired")
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
+
+// testAccCheckUserPassword checks the password the fake stores for a user
+func testAccCheckUserPassword(name string, check func(password string, expired bool) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		return check(testAccFake.userPassword(name))
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	policy := passwordPolicy{length: 20, minLower: 2, minUpper: 3, minNumeric: 4, minSpecial: 5, specialChars: "#!"}
+	seen := make(map[string]bool)
+
+	for i := 0; i < 100; i++ {
+		password, err := generatePassword(policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(password) != policy.length {
+			t.Fatalf("expected %d characters, got %q", policy.length, password)
+		}
+
+		counts := map[string]int{}
+		for _, ch := range password {
+			for _, class := range []string{passwordLower, passwordUpper, passwordNumeric, policy.specialChars} {
+				if strings.ContainsRune(class, ch) {
+					counts[class]++
+				}
+			}
+		}
+		if counts[passwordLower] < 2 || counts[passwordUpper] < 3 || counts[passwordNumeric] < 4 || counts[policy.specialChars] < 5 {
+			t.Fatalf("password %q does not follow the policy", password)
+		}
+
+		if seen[password] {
+			t.Fatalf("password %q generated twice", password)
+		}
+		seen[password] = true
+	}
+
+	policy.length = 10
+	if _, err := generatePassword(policy); err == nil {
+		t.Errorf("expected an error for a policy requiring more characters than its length")
+	}
+}
+
+const testAccUser_groups = `
+resource "artifactory_group" "lebowski" {
+	name = "acctest-lebowski"
+}
+
+resource "artifactory_user" "foobar" {
+	name   = "maude"
+	email  = "maude@domain.com"
+	groups = [ "${artifactory_group.lebowski.name}" ]
+}`
+
+const testAccUser_groupsEmailChanged = `
+resource "artifactory_group" "lebowski" {
+	name = "acctest-lebowski"
+}
+
+resource "artifactory_user" "foobar" {
+	name   = "maude"
+	email  = "maude.lebowski@domain.com"
+	groups = [ "${artifactory_group.lebowski.name}" ]
+}`
+
+func TestAccUser_groups(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_user.foobar"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckUserDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccUser_groups,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "groups.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "internal_password_disabled", "false"),
+				),
+			},
+			resource.TestStep{
+				// removing the user from the group is detected
+				PreConfig:          func() { testAccFake.setUserGroups("maude") },
+				Config:             testAccUser_groups,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			resource.TestStep{
+				// joining a default group is not reported, and kept on update
+				PreConfig: func() { testAccFake.setUserGroups("maude", "acctest-lebowski", "readers") },
+				Config:    testAccUser_groupsEmailChanged,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "groups.#", "1"),
+					testAccCheckUserGroups("maude", "acctest-lebowski", "readers"),
+				),
+			},
+		},
+	})
+}
+
+const testAccUser_manageDefaultGroups = `
+resource "artifactory_user" "foobar" {
+	name                  = "maude"
+	email                 = "maude@domain.com"
+	manage_default_groups = true
+}`
+
+func TestAccUser_manageDefaultGroups(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_user.foobar"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckUserDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				// Artifactory adds the user to the default groups on creation
+				Config:             testAccUser_manageDefaultGroups,
+				ExpectNonEmptyPlan: true,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "groups.#", "1"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccUser_manageDefaultGroups,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "groups.#", "0"),
+					testAccCheckUserGroups("maude"),
+				),
+			},
+		},
+	})
+}
+
+const testAccUser_defaultGroup = `
+resource "artifactory_user" "foobar" {
+	name   = "maude"
+	email  = "maude@domain.com"
+	groups = [ "readers" ]
+}`
+
+func TestAccUser_groupsWithoutMembers(t *testing.T) {
+	testAccFakeOnly(t)
+	testAccFake.reset()
+	resourceName := "artifactory_user.foobar"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckUserDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				// the members of a group are only needed by the group resources
+				Config: testAccUser_defaultGroup,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "groups.#", "1"),
+					func(s *terraform.State) error {
+						if n := testAccFake.groupUserLookups(); n != 0 {
+							return fmt.Errorf("expected no group request to include the users, got %d", n)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckUserGroups checks the groups the fake stores for a user
+func testAccCheckUserGroups(name string, groups ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if groups == nil {
+			groups = []string{}
+		}
+		if got := testAccFake.userGroups(name); !reflect.DeepEqual(got, groups) {
+			return fmt.Errorf("expected user %s to be in groups %v, got %v", name, groups, got)
+		}
+		return nil
+	}
+}
@@ -1,8 +1,13 @@
 package artifactory
 
 import (
+	"fmt"
 	"log"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/webdevwilson/go-artifactory/artifactory"
@@ -18,6 +23,13 @@ func resourceVirtualRepository() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: virtualRepositoryImportStatePassthrough,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+		},
+		CustomizeDiff: resourceVirtualRepositoryCustomizeDiff,
+		SchemaVersion: 1,
+		MigrateState:  resourceVirtualRepositoryMigrateState,
 		Schema: map[string]*schema.Schema{
 			"key": &schema.Schema{
 				Type:     schema.TypeString,
@@ -32,9 +44,8 @@ func resourceVirtualRepository() *schema.Resource {
 				ForceNew:     true,
 			},
 			"repositories": &schema.Schema{
-				Type:     schema.TypeSet,
+				Type:     schema.TypeList,
 				Elem:     &schema.Schema{Type: schema.TypeString},
-				Set:      schema.HashString,
 				Optional: true,
 			},
 			"description": &schema.Schema{
@@ -60,8 +71,8 @@ func resourceVirtualRepository() *schema.Resource {
 			},
 			"repo_layout_ref": &schema.Schema{
 				Type:     schema.TypeString,
-				Default:  "maven-2-default",
 				Optional: true,
+				Computed: true,
 			},
 			"artifactory_requests_can_retrieve_remote_artifacts": &schema.Schema{
 				Type:     schema.TypeBool,
@@ -80,18 +91,51 @@ func resourceVirtualRepository() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"alpine": repositoryFormatSchema(map[string]*schema.Schema{
+				"primary_keypair_ref": &schema.Schema{
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			}),
+			"go": repositoryFormatSchema(map[string]*schema.Schema{
+				"external_dependencies_enabled": &schema.Schema{
+					Type:     schema.TypeBool,
+					Optional: true,
+				},
+				"external_dependencies_patterns": &schema.Schema{
+					Type:     schema.TypeList,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+					Optional: true,
+				},
+			}),
+			"helm": repositoryFormatSchema(map[string]*schema.Schema{
+				"retrieval_cache_period_seconds": &schema.Schema{
+					Type:     schema.TypeInt,
+					Optional: true,
+				},
+			}),
 		},
 	}
 }
 
+// virtualRepositoryPackageTypeAttributes are the attributes of virtual
+// repositories that only apply to some package types
+var virtualRepositoryPackageTypeAttributes = packageTypeAttributes{
+	"pom_repository_references_cleanup_policy": mavenPackageTypes,
+	"alpine": {"alpine"},
+	"go":     {"go"},
+	"helm":   {"helm"},
+}
+
 func newVirtualRepositoryFromResource(d *schema.ResourceData) *artifactory.VirtualRepositoryConfiguration {
-	repos := make([]string, 0, len(d.Get("repositories").(*schema.Set).List()))
+	repos := make([]string, 0, len(d.Get("repositories").([]interface{})))
 
-	for _, r := range d.Get("repositories").(*schema.Set).List() {
+	// Artifactory resolves artifacts from the repositories in this order
+	for _, r := range d.Get("repositories").([]interface{}) {
 		repos = append(repos, r.(string))
 	}
 
-	return &artifactory.VirtualRepositoryConfiguration{
+	repo := &artifactory.VirtualRepositoryConfiguration{
 		Key:                                           d.Get("key").(string),
 		RClass:                                        "virtual",
 		PackageType:                                   d.Get("package_type").(string),
@@ -100,11 +144,114 @@ func newVirtualRepositoryFromResource(d *schema.ResourceData) *artifactory.Virtu
 		Notes:                                         d.Get("notes").(string),
 		IncludesPattern:                               d.Get("includes_pattern").(string),
 		ExcludesPattern:                               d.Get("excludes_pattern").(string),
-		ArtifactoryRequestsCanRetrieveRemoteArtifacts: d.Get("artifactory_requests_can_retrieve_remote_artifacts").(bool),
+		RepoLayoutRef:                                 d.Get("repo_layout_ref").(string),
+		ArtifactoryRequestsCanRetrieveRemoteArtifacts: getBoolRef(d, "artifactory_requests_can_retrieve_remote_artifacts"),
 		KeyPair: d.Get("key_pair").(string),
 		PomRepositoryReferencesCleanupPolicy: d.Get("pom_repository_references_cleanup_policy").(string),
 		DefaultDeploymentRepo:                d.Get("default_deployment_repo").(string),
 	}
+
+	if alpine := getFormatBlock(d, "alpine"); alpine != nil {
+		repo.PrimaryKeyPairRef = artifactory.String(alpine["primary_keypair_ref"].(string))
+	}
+
+	if goBlock := getFormatBlock(d, "go"); goBlock != nil {
+		repo.ExternalDependenciesEnabled = artifactory.Bool(goBlock["external_dependencies_enabled"].(bool))
+		patterns := make([]string, 0)
+		for _, p := range goBlock["external_dependencies_patterns"].([]interface{}) {
+			patterns = append(patterns, p.(string))
+		}
+		repo.ExternalDependenciesPatterns = &patterns
+	}
+
+	if helm := getFormatBlock(d, "helm"); helm != nil {
+		repo.VirtualRetrievalCachePeriodSeconds = artifactory.Int(helm["retrieval_cache_period_seconds"].(int))
+	}
+
+	return repo
+}
+
+// resourceVirtualRepositoryCustomizeDiff validates a virtual repository at plan
+// time. Members that do not exist yet may be created by the same apply, they
+// are only checked when the repository is applied.
+func resourceVirtualRepositoryCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	if err := virtualRepositoryPackageTypeAttributes.validate(d, resourceVirtualRepository().Schema); err != nil {
+		return err
+	}
+
+	if d.Id() != "" && !d.HasChange("repositories") && !d.HasChange("default_deployment_repo") {
+		return nil
+	}
+
+	repo := &artifactory.VirtualRepositoryConfiguration{
+		Key:                   d.Get("key").(string),
+		PackageType:           d.Get("package_type").(string),
+		DefaultDeploymentRepo: d.Get("default_deployment_repo").(string),
+	}
+	for _, r := range d.Get("repositories").([]interface{}) {
+		key, _ := r.(string)
+		if key == "" || key == config.UnknownVariableValue {
+			// not known yet, the members are checked when they are applied
+			return nil
+		}
+		repo.Repositories = append(repo.Repositories, key)
+	}
+	if len(repo.Repositories) == 0 {
+		// an unknown list reads as an empty one
+		return nil
+	}
+
+	return validateVirtualRepositoryMembers(m.(artifactory.Client), repo, true)
+}
+
+// validateVirtualRepositoryMembers checks that the members of a virtual
+// repository exist and share its package type, and that the default deployment
+// repository is one of its local members. Artifactory would otherwise reject
+// or ignore them. When planning, missing members are accepted as they may be
+// created by the same apply.
+func validateVirtualRepositoryMembers(c artifactory.Client, repo *artifactory.VirtualRepositoryConfiguration, planning bool) error {
+	if len(repo.Repositories) == 0 && repo.DefaultDeploymentRepo == "" {
+		return nil
+	}
+
+	all, err := c.ListRepositories("", "")
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]artifactory.RepositoryDetails, len(all))
+	for _, r := range all {
+		existing[r.Key] = r
+	}
+
+	keys := append([]string{}, repo.Repositories...)
+	sort.Strings(keys)
+
+	problems := make([]string, 0)
+	locals := make(map[string]bool)
+	for _, key := range keys {
+		member, ok := existing[key]
+		switch {
+		case !ok && planning:
+			// may be created as a local repository by the same apply
+			locals[key] = true
+		case !ok:
+			problems = append(problems, fmt.Sprintf("repository %s does not exist", key))
+		case !strings.EqualFold(member.PackageType, repo.PackageType):
+			problems = append(problems, fmt.Sprintf("repository %s is a %s repository", key, strings.ToLower(member.PackageType)))
+		case strings.EqualFold(member.Type, "local"):
+			locals[key] = true
+		}
+	}
+
+	if repo.DefaultDeploymentRepo != "" && !locals[repo.DefaultDeploymentRepo] {
+		problems = append(problems, fmt.Sprintf("default_deployment_repo %s is not a local member", repo.DefaultDeploymentRepo))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("Invalid members of %s repository %s: %s", repo.PackageType, repo.Key, strings.Join(problems, ", "))
+	}
+	return nil
 }
 
 func resourceRepositoryExists(d *schema.ResourceData, m interface{}) (exists bool, err error) {
@@ -113,6 +260,9 @@ func resourceRepositoryExists(d *schema.ResourceData, m interface{}) (exists boo
 	var repo artifactory.VirtualRepositoryConfiguration
 
 	err = c.GetRepository(key, &repo)
+	if artifactory.IsNotFound(err) {
+		return false, nil
+	}
 	exists = (repo.Key == key)
 
 	return
@@ -122,6 +272,9 @@ func resourceVirtualRepositoryCreate(d *schema.ResourceData, m interface{}) erro
 	log.Printf("[TRACE] Creating artifactory.virtual_repository Id=%s\n", d.Get("key"))
 	c := m.(artifactory.Client)
 	repo := newVirtualRepositoryFromResource(d)
+	if err := validateVirtualRepositoryMembers(c, repo, false); err != nil {
+		return err
+	}
 	err := c.CreateRepository(repo.Key, repo)
 
 	if err != nil {
@@ -129,7 +282,12 @@ func resourceVirtualRepositoryCreate(d *schema.ResourceData, m interface{}) erro
 	}
 
 	d.SetId(repo.Key)
-	return resourceVirtualRepositoryUpdate(d, m)
+
+	if err := waitForRepository(c, repo.Key, repo, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return err
+	}
+
+	return resourceVirtualRepositoryRead(d, m)
 }
 
 func resourceVirtualRepositoryRead(d *schema.ResourceData, m interface{}) error {
@@ -140,6 +298,12 @@ func resourceVirtualRepositoryRead(d *schema.ResourceData, m interface{}) error
 
 	err := c.GetRepository(key, &repo)
 
+	if artifactory.IsNotFound(err) {
+		log.Printf("[WARN] Virtual repository %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
 	if err != nil {
 		return err
 	}
@@ -151,7 +315,8 @@ func resourceVirtualRepositoryRead(d *schema.ResourceData, m interface{}) error
 	d.Set("notes", repo.Notes)
 	d.Set("includes_pattern", repo.IncludesPattern)
 	d.Set("excludes_pattern", repo.ExcludesPattern)
-	d.Set("artifactory_requests_can_retrieve_remote_artifacts", repo.ArtifactoryRequestsCanRetrieveRemoteArtifacts)
+	d.Set("repo_layout_ref", repo.RepoLayoutRef)
+	d.Set("artifactory_requests_can_retrieve_remote_artifacts", artifactory.BoolValue(repo.ArtifactoryRequestsCanRetrieveRemoteArtifacts))
 	d.Set("key_pair", repo.KeyPair)
 	d.Set("pom_repository_references_cleanup_policy", repo.PomRepositoryReferencesCleanupPolicy)
 	d.Set("default_deployment_repo", repo.DefaultDeploymentRepo)
@@ -162,30 +327,46 @@ func resourceVirtualRepositoryRead(d *schema.ResourceData, m interface{}) error
 	}
 	d.Set("repositories", repos)
 
-	return nil
+	var patterns []string
+	if repo.ExternalDependenciesPatterns != nil {
+		patterns = *repo.ExternalDependenciesPatterns
+	}
+
+	formats := map[string]map[string]interface{}{
+		"alpine": {
+			"primary_keypair_ref": artifactory.StringValue(repo.PrimaryKeyPairRef),
+		},
+		"go": {
+			"external_dependencies_enabled":  artifactory.BoolValue(repo.ExternalDependenciesEnabled),
+			"external_dependencies_patterns": patterns,
+		},
+		"helm": {
+			"retrieval_cache_period_seconds": artifactory.IntValue(repo.VirtualRetrievalCachePeriodSeconds),
+		},
+	}
+	for key, attrs := range formats {
+		if err := setFormatBlock(d, key, attrs); err != nil {
+			return err
+		}
+	}
+
+	return virtualRepositoryPackageTypeAttributes.reset(d, resourceVirtualRepository().Schema, repo.PackageType)
 }
 
 func resourceVirtualRepositoryUpdate(d *schema.ResourceData, m interface{}) error {
 	log.Printf("[TRACE] Updating artifactory.virtual_repository Id=%s\n", d.Id())
 	c := m.(artifactory.Client)
 	repo := newVirtualRepositoryFromResource(d)
-	c.UpdateRepository(repo.Key, repo)
-
-	wait := repoCreateWait()
-	wait.Refresh = func() (interface{}, string, error) {
-		log.Printf("[DEBUG] Checking if Group %s is created", repo.Key)
-
-		newRepo := artifactory.VirtualRepositoryConfiguration{}
-		err := c.GetRepository(repo.Key, &newRepo)
-		if err != nil {
-			return newRepo, "updating", err
+	if d.HasChange("repositories") || d.HasChange("default_deployment_repo") {
+		if err := validateVirtualRepositoryMembers(c, repo, false); err != nil {
+			return err
 		}
-		log.Printf("[DEBUG] Group %s is created", repo.Key)
-		return newRepo, "updated", err
+	}
+	if err := c.UpdateRepository(repo.Key, repo); err != nil {
+		return err
 	}
 
-	_, err := wait.WaitForState()
-	if err != nil {
+	if err := waitForRepository(c, repo.Key, repo, d.Timeout(schema.TimeoutUpdate)); err != nil {
 		return err
 	}
 	return resourceVirtualRepositoryRead(d, m)
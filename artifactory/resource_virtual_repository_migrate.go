@@ -0,0 +1,58 @@
+package artifactory
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func resourceVirtualRepositoryMigrateState(v int, is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	switch v {
+	case 0:
+		log.Println("[INFO] Found artifactory_virtual_repository state v0; migrating to v1")
+		return migrateVirtualRepositoryStateV0toV1(is)
+	default:
+		return is, fmt.Errorf("Unexpected schema version: %d", v)
+	}
+}
+
+// migrateVirtualRepositoryStateV0toV1 turns the repositories set into a list.
+// The set did not keep the resolution order, so the members keep the order the
+// set listed them in, and the next refresh reads the actual order from
+// Artifactory.
+func migrateVirtualRepositoryStateV0toV1(is *terraform.InstanceState) (*terraform.InstanceState, error) {
+	if is.Empty() || is.Attributes == nil {
+		log.Println("[DEBUG] Empty InstanceState; nothing to migrate.")
+		return is, nil
+	}
+
+	log.Printf("[DEBUG] Attributes before migration: %#v", is.Attributes)
+
+	hashes := make([]string, 0)
+	members := make(map[string]string)
+	for k, v := range is.Attributes {
+		if !strings.HasPrefix(k, "repositories.") || k == "repositories.#" {
+			continue
+		}
+
+		hash := strings.TrimPrefix(k, "repositories.")
+		hashes = append(hashes, hash)
+		members[hash] = v
+		delete(is.Attributes, k)
+	}
+
+	sort.Strings(hashes)
+	for i, hash := range hashes {
+		is.Attributes[fmt.Sprintf("repositories.%d", i)] = members[hash]
+	}
+	if _, ok := is.Attributes["repositories.#"]; ok {
+		is.Attributes["repositories.#"] = strconv.Itoa(len(hashes))
+	}
+
+	log.Printf("[DEBUG] Attributes after migration: %#v", is.Attributes)
+	return is, nil
+}
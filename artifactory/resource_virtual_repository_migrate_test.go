@@ -0,0 +1,79 @@
+package artifactory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestVirtualRepositoryMigrateState(t *testing.T) {
+	cases := map[string]struct {
+		StateVersion int
+		Attributes   map[string]string
+		Expected     map[string]string
+	}{
+		"v0_members": {
+			StateVersion: 0,
+			Attributes: map[string]string{
+				"key":                     "npm",
+				"repositories.#":          "3",
+				"repositories.3570384371": "npm-remote",
+				"repositories.1033543035": "npm-local",
+				"repositories.214975871":  "npm-cache",
+			},
+			Expected: map[string]string{
+				"key":            "npm",
+				"repositories.#": "3",
+				"repositories.0": "npm-local",
+				"repositories.1": "npm-cache",
+				"repositories.2": "npm-remote",
+			},
+		},
+		"v0_no_members": {
+			StateVersion: 0,
+			Attributes: map[string]string{
+				"key":            "npm",
+				"repositories.#": "0",
+			},
+			Expected: map[string]string{
+				"key":            "npm",
+				"repositories.#": "0",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		is := &terraform.InstanceState{
+			ID:         "npm",
+			Attributes: tc.Attributes,
+		}
+		is, err := resourceVirtualRepositoryMigrateState(tc.StateVersion, is, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", name, err)
+		}
+
+		if !reflect.DeepEqual(is.Attributes, tc.Expected) {
+			t.Errorf("%s: expected %v, got %v", name, tc.Expected, is.Attributes)
+		}
+	}
+}
+
+func TestVirtualRepositoryMigrateState_empty(t *testing.T) {
+	var is *terraform.InstanceState
+
+	// should handle nil
+	is, err := resourceVirtualRepositoryMigrateState(0, is, nil)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+	if is != nil {
+		t.Fatalf("expected nil instancestate, got: %#v", is)
+	}
+
+	// should handle non-nil but empty
+	is = &terraform.InstanceState{}
+	if _, err := resourceVirtualRepositoryMigrateState(0, is, nil); err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+}
@@ -1,9 +1,13 @@
 package artifactory
 
 import (
+	"fmt"
+	"net/http"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/webdevwilson/go-artifactory/artifactory"
 )
 
 const testAccVirtualRepository_basic = `
@@ -57,7 +61,6 @@ resource "artifactory_virtual_repository" "npm" {
     repo_layout_ref                                    = "npm-default"
     artifactory_requests_can_retrieve_remote_artifacts = false
     key_pair                                           = "keypair"
-    pom_repository_references_cleanup_policy           = "discard_any_reference"
     default_deployment_repo                            = "${artifactory_local_repository.npm_private.key}"
     debian_trivial_layout                              = false
     repositories                                       = [
@@ -85,7 +88,6 @@ func TestAccVirtualRepository_full(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "repo_layout_ref", "npm-default"),
 					resource.TestCheckResourceAttr(resourceName, "artifactory_requests_can_retrieve_remote_artifacts", "false"),
 					resource.TestCheckResourceAttr(resourceName, "key_pair", "keypair"),
-					resource.TestCheckResourceAttr(resourceName, "pom_repository_references_cleanup_policy", "discard_any_reference"),
 					resource.TestCheckResourceAttr(resourceName, "default_deployment_repo", "acctest-virtual-npm-private"),
 					resource.TestCheckResourceAttr(resourceName, "repositories.#", "2"),
 				),
@@ -93,3 +95,346 @@ func TestAccVirtualRepository_full(t *testing.T) {
 		},
 	})
 }
+
+const testAccVirtualRepository_formats = `
+resource "artifactory_virtual_repository" "go" {
+	key          = "acctest-virtual-go"
+	package_type = "go"
+
+	go {
+		external_dependencies_enabled  = true
+		external_dependencies_patterns = [ "**/github.com/**", "**/go.googlesource.com/**" ]
+	}
+}
+
+resource "artifactory_virtual_repository" "helm" {
+	key          = "acctest-virtual-helm"
+	package_type = "helm"
+
+	helm {
+		retrieval_cache_period_seconds = 300
+	}
+}
+
+resource "artifactory_virtual_repository" "alpine" {
+	key          = "acctest-virtual-alpine"
+	package_type = "alpine"
+
+	alpine {
+		primary_keypair_ref = "acctest-keypair"
+	}
+}`
+
+const testAccVirtualRepository_formatsRemoved = `
+resource "artifactory_virtual_repository" "go" {
+	key          = "acctest-virtual-go"
+	package_type = "go"
+}`
+
+func TestAccVirtualRepository_formats(t *testing.T) {
+	resourceName := "artifactory_virtual_repository.go"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccVirtualRepository_formats,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "go.0.external_dependencies_enabled", "true"),
+					resource.TestCheckResourceAttr(resourceName, "go.0.external_dependencies_patterns.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "go.0.external_dependencies_patterns.0", "**/github.com/**"),
+					resource.TestCheckResourceAttr("artifactory_virtual_repository.helm", "helm.0.retrieval_cache_period_seconds", "300"),
+					resource.TestCheckResourceAttr("artifactory_virtual_repository.alpine", "alpine.0.primary_keypair_ref", "acctest-keypair"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			resource.TestStep{
+				// removing the block unsets the settings
+				Config: testAccVirtualRepository_formatsRemoved,
+				Check:  resource.TestCheckResourceAttr(resourceName, "go.#", "0"),
+			},
+		},
+	})
+}
+
+const testAccVirtualRepository_maven = `
+resource "artifactory_virtual_repository" "foobar" {
+	key                                      = "acctest-virtual-maven"
+	package_type                             = "maven"
+	pom_repository_references_cleanup_policy = "discard_any_reference"
+}`
+
+const testAccVirtualRepository_npmWithPomCleanup = `
+resource "artifactory_virtual_repository" "foobar" {
+	key                                      = "acctest-virtual-npm"
+	package_type                             = "npm"
+	pom_repository_references_cleanup_policy = "discard_any_reference"
+}`
+
+func TestAccVirtualRepository_packageTypeAttributes(t *testing.T) {
+	resourceName := "artifactory_virtual_repository.foobar"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccVirtualRepository_maven,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "repo_layout_ref", "maven-2-default"),
+					resource.TestCheckResourceAttr(resourceName, "pom_repository_references_cleanup_policy", "discard_any_reference"),
+				),
+			},
+		},
+	})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccVirtualRepository_npmWithPomCleanup,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("pom_repository_references_cleanup_policy cannot be set on npm repositories"),
+			},
+		},
+	})
+}
+
+const testAccVirtualRepository_members = `
+resource "artifactory_local_repository" "npm" {
+	key          = "acctest-virtual-members-npm"
+	package_type = "npm"
+}
+
+resource "artifactory_local_repository" "maven" {
+	key          = "acctest-virtual-members-maven"
+	package_type = "maven"
+}
+
+resource "artifactory_remote_repository" "npm" {
+	key          = "acctest-virtual-members-npm-remote"
+	package_type = "npm"
+	url          = "https://registry.npmjs.org/"
+}`
+
+const testAccVirtualRepository_validMembers = testAccVirtualRepository_members + `
+
+resource "artifactory_virtual_repository" "foobar" {
+	key                     = "acctest-virtual-members"
+	package_type            = "npm"
+	repositories            = ["${artifactory_local_repository.npm.key}"]
+	default_deployment_repo = "${artifactory_local_repository.npm.key}"
+}`
+
+const testAccVirtualRepository_invalidMembers = testAccVirtualRepository_members + `
+
+resource "artifactory_virtual_repository" "foobar" {
+	key                     = "acctest-virtual-members"
+	package_type            = "npm"
+	repositories            = [
+		"${artifactory_local_repository.npm.key}",
+		"${artifactory_local_repository.maven.key}",
+		"${artifactory_remote_repository.npm.key}",
+	]
+	default_deployment_repo = "${artifactory_remote_repository.npm.key}"
+}`
+
+const testAccVirtualRepository_missingMember = `
+resource "artifactory_virtual_repository" "foobar" {
+	key          = "acctest-virtual-members"
+	package_type = "npm"
+	repositories = ["acctest-virtual-members-typo"]
+}`
+
+func TestAccVirtualRepository_invalidMembers(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy("artifactory_local_repository.npm"),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccVirtualRepository_validMembers,
+			},
+			resource.TestStep{
+				// existing members are checked by the plan
+				Config:   testAccVirtualRepository_invalidMembers,
+				PlanOnly: true,
+				ExpectError: regexp.MustCompile("Invalid members of npm repository acctest-virtual-members: " +
+					"repository acctest-virtual-members-maven is a maven repository, " +
+					"default_deployment_repo acctest-virtual-members-npm-remote is not a local member"),
+			},
+		},
+	})
+}
+
+func TestAccVirtualRepository_missingMember(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				// a missing member could be created by the same apply, it is only
+				// rejected when the repository is applied
+				Config:      testAccVirtualRepository_missingMember,
+				ExpectError: regexp.MustCompile("repository acctest-virtual-members-typo does not exist"),
+			},
+		},
+	})
+}
+
+const testAccVirtualRepository_unmanagedMember = `
+resource "artifactory_virtual_repository" "foobar" {
+	key          = "acctest-virtual-unmanaged"
+	package_type = "npm"
+	description  = "%s"
+	repositories = [ "acctest-virtual-unmanaged-npm" ]
+}`
+
+func TestAccVirtualRepository_unchangedMembers(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_virtual_repository.foobar"
+	member := "acctest-virtual-unmanaged-npm"
+	defer testAccFake.removeOutOfBand("repositories/" + member)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				PreConfig: func() {
+					c := artifactory.NewClient(testAccFake.username, testAccFake.password, testAccFake.URL, http.DefaultClient)
+					repo := &artifactory.LocalRepositoryConfiguration{Key: member, RClass: "local", PackageType: "npm"}
+					if err := c.CreateRepository(member, repo); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: fmt.Sprintf(testAccVirtualRepository_unmanagedMember, "desc"),
+			},
+			resource.TestStep{
+				// members are only validated when they change
+				PreConfig: func() {
+					testAccFake.updateOutOfBand("repositories/"+member, map[string]interface{}{"packageType": "maven"})
+				},
+				Config: fmt.Sprintf(testAccVirtualRepository_unmanagedMember, "changed"),
+				Check:  resource.TestCheckResourceAttr(resourceName, "description", "changed"),
+			},
+		},
+	})
+}
+
+const testAccVirtualRepository_ordered = `
+resource "artifactory_local_repository" "npm" {
+	key          = "acctest-virtual-order-local"
+	package_type = "npm"
+}
+
+resource "artifactory_remote_repository" "npm" {
+	key          = "acctest-virtual-order-remote"
+	package_type = "npm"
+	url          = "https://registry.npmjs.org/"
+}
+
+resource "artifactory_virtual_repository" "foobar" {
+	key          = "acctest-virtual-order"
+	package_type = "npm"
+	repositories = [
+		"${artifactory_%s_repository.npm.key}",
+		"${artifactory_%s_repository.npm.key}",
+	]
+}`
+
+func TestAccVirtualRepository_ordered(t *testing.T) {
+	resourceName := "artifactory_virtual_repository.foobar"
+	localFirst := fmt.Sprintf(testAccVirtualRepository_ordered, "local", "remote")
+	remoteFirst := fmt.Sprintf(testAccVirtualRepository_ordered, "remote", "local")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: localFirst,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "repositories.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "repositories.0", "acctest-virtual-order-local"),
+					resource.TestCheckResourceAttr(resourceName, "repositories.1", "acctest-virtual-order-remote"),
+				),
+			},
+			resource.TestStep{
+				Config: remoteFirst,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "repositories.0", "acctest-virtual-order-remote"),
+					resource.TestCheckResourceAttr(resourceName, "repositories.1", "acctest-virtual-order-local"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVirtualRepository_reorderedOutOfBand(t *testing.T) {
+	testAccFakeOnly(t)
+	resourceName := "artifactory_virtual_repository.foobar"
+	localFirst := fmt.Sprintf(testAccVirtualRepository_ordered, "local", "remote")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: localFirst,
+			},
+			resource.TestStep{
+				PreConfig: func() {
+					testAccFake.updateOutOfBand("repositories/acctest-virtual-order", map[string]interface{}{
+						"repositories": []interface{}{"acctest-virtual-order-remote", "acctest-virtual-order-local"},
+					})
+				},
+				Config:             localFirst,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			resource.TestStep{
+				Config: localFirst,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "repositories.0", "acctest-virtual-order-local"),
+					resource.TestCheckResourceAttr(resourceName, "repositories.1", "acctest-virtual-order-remote"),
+				),
+			},
+		},
+	})
+}
+
+const testAccVirtualRepository_noMembers = `
+resource "artifactory_virtual_repository" "foobar" {
+	key          = "acctest-virtual-order"
+	package_type = "npm"
+}`
+
+func TestAccVirtualRepository_membersRemoved(t *testing.T) {
+	resourceName := "artifactory_virtual_repository.foobar"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccCheckRepositoryDestroy(resourceName),
+		Providers:    testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccVirtualRepository_ordered, "local", "remote"),
+				Check:  resource.TestCheckResourceAttr(resourceName, "repositories.#", "2"),
+			},
+			resource.TestStep{
+				Config: testAccVirtualRepository_noMembers,
+				Check:  resource.TestCheckResourceAttr(resourceName, "repositories.#", "0"),
+			},
+		},
+	})
+}
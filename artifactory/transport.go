@@ -0,0 +1,180 @@
+package artifactory
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// newTransport returns the transport used to reach Artifactory, configured
+// from the TLS and proxy arguments of the provider
+func newTransport(d *schema.ResourceData) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig, err := newTLSConfig(d)
+	if err != nil {
+		return nil, err
+	}
+	t.TLSClientConfig = tlsConfig
+
+	proxy, err := newProxyFunc(d.Get("proxy_url").(string), d.Get("no_proxy").(string))
+	if err != nil {
+		return nil, err
+	}
+	t.Proxy = proxy
+
+	return t, nil
+}
+
+func newTLSConfig(d *schema.ResourceData) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+	}
+
+	caFile := d.Get("ca_cert_file").(string)
+	caPEM := d.Get("ca_cert_pem").(string)
+	if caFile != "" && caPEM != "" {
+		return nil, fmt.Errorf("Only one of ca_cert_file and ca_cert_pem can be set")
+	}
+
+	if caFile != "" {
+		b, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading ca_cert_file: %s", err)
+		}
+		caPEM = string(b)
+	}
+
+	if caPEM != "" {
+		// trust the given CA in addition to the ones of the system
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(caPEM)) {
+			return nil, fmt.Errorf("No PEM encoded certificate found in the CA certificate")
+		}
+		config.RootCAs = pool
+	}
+
+	clientCert := d.Get("client_cert").(string)
+	clientKey := d.Get("client_key").(string)
+	if (clientCert == "") != (clientKey == "") {
+		return nil, fmt.Errorf("Both client_cert and client_key must be set for client certificate authentication")
+	}
+
+	if clientCert != "" {
+		certPEM, err := pemOrFile(clientCert)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading client_cert: %s", err)
+		}
+		keyPEM, err := pemOrFile(clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading client_key: %s", err)
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("Error loading client certificate: %s", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// pemOrFile returns v if it is PEM encoded, otherwise the content of the file named v
+func pemOrFile(v string) ([]byte, error) {
+	if strings.Contains(v, "-----BEGIN") {
+		return []byte(v), nil
+	}
+	return ioutil.ReadFile(v)
+}
+
+// newProxyFunc returns the proxy to use for a request. proxyURL replaces the
+// proxy configured by the environment, and the hosts in noProxy are always
+// reached directly.
+func newProxyFunc(proxyURL, noProxy string) (func(*http.Request) (*url.URL, error), error) {
+	proxy := http.ProxyFromEnvironment
+
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing proxy_url: %s", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("proxy_url must use the http, https or socks5 scheme, got %q", proxyURL)
+		}
+		proxy = http.ProxyURL(u)
+	}
+
+	if noProxy == "" {
+		return proxy, nil
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL, noProxy) {
+			return nil, nil
+		}
+		return proxy(req)
+	}, nil
+}
+
+// bypassProxy reports whether u matches the comma separated list noProxy of
+// host names, domains (matching their sub domains), IP addresses and CIDR
+// ranges, each optionally with a port. "*" matches every host.
+func bypassProxy(u *url.URL, noProxy string) bool {
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	ip := net.ParseIP(host)
+
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		entryHost, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+
+		domain := strings.TrimPrefix(entryHost, ".")
+		name := strings.ToLower(host)
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
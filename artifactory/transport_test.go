@@ -0,0 +1,343 @@
+package artifactory
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// testCertificate is a certificate and its key, both PEM encoded
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM string
+	keyPEM  string
+}
+
+// newTestCertificate issues a certificate signed by parent, or a self-signed
+// CA certificate when parent is nil
+func newTestCertificate(t *testing.T, parent *testCertificate, template *x509.Certificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		keyPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+	}
+}
+
+func TestProvider_tls(t *testing.T) {
+	testAccFakeOnly(t)
+
+	ca := newTestCertificate(t, nil, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "acctest CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	})
+	server := newTestCertificate(t, ca, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "artifactory"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	client := newTestCertificate(t, ca, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "terraform"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+
+	dir, err := ioutil.TempDir("", "artifactory-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	clientCertFile := filepath.Join(dir, "client.pem")
+	clientKeyFile := filepath.Join(dir, "client-key.pem")
+	for name, content := range map[string]string{caFile: ca.certPEM, clientCertFile: client.certPEM, clientKeyFile: client.keyPEM} {
+		if err := ioutil.WriteFile(name, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	serverCert, err := tls.X509KeyPair([]byte(server.certPEM), []byte(server.keyPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	srv := httptest.NewUnstartedServer(testAccFake)
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	mtls := httptest.NewUnstartedServer(testAccFake)
+	mtls.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	mtls.StartTLS()
+	defer mtls.Close()
+
+	cases := []struct {
+		name   string
+		url    string
+		config map[string]interface{}
+		err    string
+	}{
+		{
+			name: "unknown CA",
+			url:  srv.URL,
+			err:  "certificate",
+		},
+		{
+			name:   "ca_cert_file",
+			url:    srv.URL,
+			config: map[string]interface{}{"ca_cert_file": caFile},
+		},
+		{
+			name:   "ca_cert_pem",
+			url:    srv.URL,
+			config: map[string]interface{}{"ca_cert_pem": ca.certPEM},
+		},
+		{
+			name:   "both CA arguments",
+			url:    srv.URL,
+			config: map[string]interface{}{"ca_cert_file": caFile, "ca_cert_pem": ca.certPEM},
+			err:    "Only one of ca_cert_file and ca_cert_pem",
+		},
+		{
+			name:   "invalid CA",
+			url:    srv.URL,
+			config: map[string]interface{}{"ca_cert_pem": "not a certificate"},
+			err:    "No PEM encoded certificate",
+		},
+		{
+			name:   "insecure_skip_verify",
+			url:    srv.URL,
+			config: map[string]interface{}{"insecure_skip_verify": true},
+		},
+		{
+			name:   "client certificate required",
+			url:    mtls.URL,
+			config: map[string]interface{}{"ca_cert_pem": ca.certPEM},
+			err:    "Error connecting to Artifactory",
+		},
+		{
+			name:   "client certificate files",
+			url:    mtls.URL,
+			config: map[string]interface{}{"ca_cert_pem": ca.certPEM, "client_cert": clientCertFile, "client_key": clientKeyFile},
+		},
+		{
+			name:   "client certificate PEM",
+			url:    mtls.URL,
+			config: map[string]interface{}{"ca_cert_pem": ca.certPEM, "client_cert": client.certPEM, "client_key": client.keyPEM},
+		},
+		{
+			name:   "client certificate without key",
+			url:    mtls.URL,
+			config: map[string]interface{}{"ca_cert_pem": ca.certPEM, "client_cert": client.certPEM},
+			err:    "Both client_cert and client_key",
+		},
+	}
+
+	for _, tc := range cases {
+		raw := map[string]interface{}{
+			"url":      tc.url,
+			"username": testAccFake.username,
+			"password": testAccFake.password,
+		}
+		for k, v := range tc.config {
+			raw[k] = v
+		}
+
+		rc, err := config.NewRawConfig(raw)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+
+		err = Provider().Configure(terraform.NewResourceConfig(rc))
+		if tc.err == "" && err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+		}
+		if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("%s: expected error containing %q, got: %v", tc.name, tc.err, err)
+		}
+	}
+}
+
+func TestBypassProxy(t *testing.T) {
+	cases := []struct {
+		url     string
+		noProxy string
+		bypass  bool
+	}{
+		{"https://artifactory.example.com", "", false},
+		{"https://artifactory.example.com", "*", true},
+		{"https://artifactory.example.com", "artifactory.example.com", true},
+		{"https://artifactory.example.com", "ARTIFACTORY.example.com", true},
+		{"https://artifactory.example.com", "example.com", true},
+		{"https://artifactory.example.com", ".example.com", true},
+		{"https://artifactory.example.com", "ample.com", false},
+		{"https://artifactory.example.com", "other.com, example.com", true},
+		{"https://artifactory.example.com", "example.com:443", true},
+		{"https://artifactory.example.com", "example.com:8443", false},
+		{"https://artifactory.example.com:8443", "example.com:8443", true},
+		{"http://10.1.2.3:8081", "10.0.0.0/8", true},
+		{"http://10.1.2.3:8081", "192.168.0.0/16", false},
+		{"http://10.1.2.3:8081", "10.1.2.3", true},
+		{"http://10.1.2.3:8081", "10.1.2.4", false},
+		{"http://[::1]:8081", "::1", true},
+	}
+
+	for _, tc := range cases {
+		u, err := url.Parse(tc.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bypass := bypassProxy(u, tc.noProxy); bypass != tc.bypass {
+			t.Errorf("%s with no_proxy %q: expected bypass %t, got %t", tc.url, tc.noProxy, tc.bypass, bypass)
+		}
+	}
+}
+
+func TestProvider_proxy(t *testing.T) {
+	testAccFakeOnly(t)
+
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a forward proxy receives the absolute URL of the request
+		if r.URL.Host != "" {
+			atomic.AddInt32(&proxied, 1)
+		}
+		testAccFake.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	cases := []struct {
+		name    string
+		config  map[string]interface{}
+		proxied bool
+		err     string
+	}{
+		{
+			name:    "proxy_url",
+			config:  map[string]interface{}{"proxy_url": proxy.URL},
+			proxied: true,
+		},
+		{
+			name:   "no_proxy",
+			config: map[string]interface{}{"proxy_url": proxy.URL, "no_proxy": "localhost,127.0.0.1"},
+		},
+		{
+			name:   "unsupported scheme",
+			config: map[string]interface{}{"proxy_url": "ftp://proxy.example.com"},
+			err:    "proxy_url must use the http, https or socks5 scheme",
+		},
+	}
+
+	for _, tc := range cases {
+		atomic.StoreInt32(&proxied, 0)
+
+		raw := map[string]interface{}{
+			"url":      testAccFake.URL,
+			"username": testAccFake.username,
+			"password": testAccFake.password,
+		}
+		for k, v := range tc.config {
+			raw[k] = v
+		}
+
+		rc, err := config.NewRawConfig(raw)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+
+		err = Provider().Configure(terraform.NewResourceConfig(rc))
+		if tc.err == "" && err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+		}
+		if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("%s: expected error containing %q, got: %v", tc.name, tc.err, err)
+		}
+		if got := atomic.LoadInt32(&proxied) > 0; got != tc.proxied {
+			t.Errorf("%s: expected proxied %t, got %t", tc.name, tc.proxied, got)
+		}
+	}
+}
+
+func TestProvider_requestTimeout(t *testing.T) {
+	testAccFakeOnly(t)
+	defer testAccFake.reset()
+	testAccFake.addFault(&fakeFault{method: "GET", path: "system/ping", delay: 2 * time.Second})
+
+	rc, err := config.NewRawConfig(map[string]interface{}{
+		"url":             testAccFake.URL,
+		"username":        testAccFake.username,
+		"password":        testAccFake.password,
+		"request_timeout": "100ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	err = Provider().Configure(terraform.NewResourceConfig(rc))
+	if err == nil || !strings.Contains(err.Error(), "context deadline exceeded") {
+		t.Errorf("expected the request to time out, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the request to be cancelled after 100ms, took %s", elapsed)
+	}
+}